@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/server"
 	pkg "{{cookiecutter.project_name}}/pkg/log"
 	"{{cookiecutter.project_name}}/pkg/nacos"
 	"{{cookiecutter.project_name}}/pkg/profile"
@@ -11,22 +12,25 @@ import (
 	"github.com/go-kratos/kratos/v2/config"
 	"github.com/go-kratos/kratos/v2/config/file"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 	"github.com/go-kratos/kratos/v2/transport/http"
 	_ "go.uber.org/automaxprocs"
 )
 
-func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, cc *conf.Config, nac *nacos.Client) *kratos.App {
+func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, as *server.AdminServer, cc *conf.Config, nac *nacos.Client) *kratos.App {
+	servers := []transport.Server{gs, hs}
+	// 管理端口未启用时为 nil
+	if as != nil {
+		servers = append(servers, as)
+	}
 	options := []kratos.Option{
 		kratos.ID(cc.GetGlobal().Id),
 		kratos.Name(cc.GetGlobal().AppName),
 		kratos.Version(cc.GetGlobal().Version),
 		kratos.Metadata(map[string]string{}),
 		kratos.Logger(logger),
-		kratos.Server(
-			gs,
-			hs,
-		),
+		kratos.Server(servers...),
 	}
 	if cc.Nacos.Enable && nac != nil {
 		options = append(options, kratos.Registrar(nac))
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"{{cookiecutter.project_name}}/internal/data"
+	"{{cookiecutter.project_name}}/pkg/database"
+	pkg "{{cookiecutter.project_name}}/pkg/log"
+	"{{cookiecutter.project_name}}/pkg/migrate"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = `Usage: %s migrate <command> [flags]
+
+Commands:
+  up               apply all pending migrations
+  down [-steps n]  roll back the last n applied migrations (default 1)
+  status           show applied and pending migrations
+  create [-dir d] <name>
+                   create empty up/down files for every dialect under -dir
+
+Flags:
+`
+
+// Migrate 执行 migrate 子命令，数据库连接使用本地配置中的 data.database
+func Migrate(args []string) error {
+	fset := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dir := fset.String("dir", "internal/data/migrations", "migrations directory used by create")
+	steps := fset.Int("steps", 1, "number of migrations to roll back")
+	fset.Usage = func() {
+		fmt.Fprintf(fset.Output(), migrateUsage, os.Args[0])
+		fset.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fset.Usage()
+		return flag.ErrHelp
+	}
+	command := args[0]
+	if err := fset.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch command {
+	case "up", "down", "status":
+	case "create":
+		if fset.NArg() != 1 {
+			return errors.New("migrate create requires a name")
+		}
+		files, err := migrate.Create(*dir, fset.Arg(0))
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			fmt.Println("created", f)
+		}
+		return nil
+	default:
+		fset.Usage()
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+
+	cc, err := loadLocalConfig()
+	if err != nil {
+		return err
+	}
+	logger, err := pkg.New(cc.Log, cc.Global)
+	if err != nil {
+		return err
+	}
+	if cc.GetData().GetDatabase().GetDriver() == "" {
+		return errors.New("data.database is not configured")
+	}
+	db, err := database.Open(cc.GetData().GetDatabase(), logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	m, err := migrate.New(db, data.Migrations(), logger)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx, *steps)
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range list {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+// Package docs 将 make api 生成的 api/openapi.yaml 嵌入二进制，运行时无需依赖工作目录下的文件
+package docs
+
+import "embed"
+
+// files 使用 * 而非 api，未生成文档时也能编译
+//
+//go:embed *
+var files embed.FS
+
+// OpenAPI 返回编译时嵌入的 api/openapi.yaml，未生成时返回 nil
+func OpenAPI() []byte {
+	data, err := files.ReadFile("api/openapi.yaml")
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -3,4 +3,5 @@ package biz
 import "github.com/google/wire"
 
 // ProviderSet is biz providers.
-var ProviderSet = wire.NewSet(NewGreeterUsecase)
+var ProviderSet = wire.NewSet(NewGreeterUsecase, NewPolicyUsecase, NewCaptchaUsecase, NewUserUsecase, NewTokenCheck, NewDictUsecase, NewSettingsUsecase,
+	NewFileUsecase)
@@ -0,0 +1,61 @@
+package biz
+
+import (
+	"context"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/captcha"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ErrCaptchaInvalid is captcha wrong, used or expired.
+var ErrCaptchaInvalid = captcha.ErrorCaptchaInvalid("captcha is invalid or expired")
+
+// Captcha is a generated captcha.
+type Captcha struct {
+	ID     string
+	Image  string
+	Expire time.Duration
+}
+
+// CaptchaRepo is a Captcha repo.
+type CaptchaRepo interface {
+	Generate(context.Context) (*Captcha, error)
+	// Verify reports whether answer is right, the captcha can not be verified again either way.
+	Verify(ctx context.Context, id, answer string) (bool, error)
+}
+
+// CaptchaUsecase is a Captcha usecase.
+type CaptchaUsecase struct {
+	repo CaptchaRepo
+	log  *log.Helper
+}
+
+// NewCaptchaUsecase new a Captcha usecase.
+func NewCaptchaUsecase(repo CaptchaRepo, logger log.Logger) *CaptchaUsecase {
+	return &CaptchaUsecase{repo: repo, log: log.NewHelper(logger)}
+}
+
+// Generate generates a Captcha.
+func (uc *CaptchaUsecase) Generate(ctx context.Context) (*Captcha, error) {
+	c, err := uc.repo.Generate(ctx)
+	if err != nil {
+		return nil, captcha.ErrorCaptchaGenerateFailed("generate captcha: %v", err).WithCause(err)
+	}
+	return c, nil
+}
+
+// Verify checks the answer of a Captcha, returns ErrCaptchaInvalid if it is wrong, used or expired.
+// Login and other handlers protected by a captcha should call it before checking credentials.
+func (uc *CaptchaUsecase) Verify(ctx context.Context, id, answer string) error {
+	ok, err := uc.repo.Verify(ctx, id, answer)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorf("verify captcha: %v", err)
+		return ErrCaptchaInvalid.WithCause(err)
+	}
+	if !ok {
+		return ErrCaptchaInvalid
+	}
+	return nil
+}
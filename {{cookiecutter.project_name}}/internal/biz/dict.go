@@ -0,0 +1,208 @@
+package biz
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/dict"
+	"{{cookiecutter.project_name}}/pkg/notify"
+	"{{cookiecutter.project_name}}/pkg/query"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// TopicDict is the Notifier topic of Dict changes, the event key is the Dict code.
+const TopicDict = "dict"
+
+var (
+	// ErrDictNotFound is dict not found.
+	ErrDictNotFound = dict.ErrorDictNotFound("dict not found")
+	// IsDictNotFound reports whether err is ErrDictNotFound.
+	IsDictNotFound = dict.IsDictNotFound
+	// ErrDictAlreadyExists is the dict code is already used.
+	ErrDictAlreadyExists = dict.ErrorDictAlreadyExists("dict already exists")
+	// ErrDictVersionConflict is the dict was changed by another request.
+	ErrDictVersionConflict = dict.ErrorDictVersionConflict("dict was modified by another request")
+)
+
+// Dict is a data dictionary, an ordered list of value/label items identified by Code.
+type Dict struct {
+	Code        string
+	Name        string
+	Description string
+	Items       []*DictItem
+	Version     int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// DictItem is an item of a Dict.
+type DictItem struct {
+	Value    string
+	Label    string
+	Sort     int32
+	Disabled bool
+	Extra    string
+}
+
+// DictRepo is a Dict repo.
+type DictRepo interface {
+	// List returns a page of Dicts without items, an invalid page returns InvalidQuery.
+	List(context.Context, query.PageRequest) (*query.Page[*Dict], error)
+	// FindByCode returns the Dict with all items ordered by Sort, or ErrDictNotFound.
+	FindByCode(context.Context, string) (*Dict, error)
+	Create(context.Context, *Dict) error
+	// Update replaces the Dict and its items and increments the stored version.
+	// It returns ErrDictVersionConflict if the stored version is not d.Version.
+	Update(ctx context.Context, d *Dict) error
+	// Delete returns ErrDictNotFound if the Dict does not exist.
+	Delete(context.Context, string) error
+}
+
+// DictUsecase is a Dict usecase.
+type DictUsecase struct {
+	repo     DictRepo
+	tx       Transaction
+	notifier Notifier
+	log      *log.Helper
+}
+
+// NewDictUsecase new a Dict usecase.
+func NewDictUsecase(repo DictRepo, tx Transaction, notifier Notifier, logger log.Logger) *DictUsecase {
+	return &DictUsecase{repo: repo, tx: tx, notifier: notifier, log: log.NewHelper(logger)}
+}
+
+// ListDict lists a page of Dicts without items.
+func (uc *DictUsecase) ListDict(ctx context.Context, page query.PageRequest) (*query.Page[*Dict], error) {
+	result, err := uc.repo.List(ctx, page)
+	if err != nil && !IsInvalidQuery(err) {
+		return nil, dict.ErrorGetDictFailed("list dict: %v", err).WithCause(err)
+	}
+	return result, err
+}
+
+// GetDict gets a Dict by code, disabled items are removed unless includeDisabled is set.
+func (uc *DictUsecase) GetDict(ctx context.Context, code string, includeDisabled bool) (*Dict, error) {
+	d, err := uc.repo.FindByCode(ctx, code)
+	if err != nil {
+		if IsDictNotFound(err) {
+			return nil, err
+		}
+		return nil, dict.ErrorGetDictFailed("get dict: %v", err).WithCause(err)
+	}
+	if !includeDisabled {
+		items := make([]*DictItem, 0, len(d.Items))
+		for _, item := range d.Items {
+			if !item.Disabled {
+				items = append(items, item)
+			}
+		}
+		d.Items = items
+	}
+	return d, nil
+}
+
+// CreateDict creates a Dict, returns ErrDictAlreadyExists if the code is used.
+func (uc *DictUsecase) CreateDict(ctx context.Context, d *Dict) (*Dict, error) {
+	if err := checkDictItems(d.Items); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	d = &Dict{Code: d.Code, Name: d.Name, Description: d.Description, Items: d.Items, Version: 1, CreatedAt: now, UpdatedAt: now}
+	err := uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if _, err := uc.repo.FindByCode(ctx, d.Code); err == nil {
+			return ErrDictAlreadyExists
+		} else if !IsDictNotFound(err) {
+			return err
+		}
+		return uc.repo.Create(ctx, d)
+	})
+	if err != nil {
+		return nil, uc.saveError(err)
+	}
+	uc.publish(ctx, d.Code, notify.ActionCreate, d.Version)
+	return d, nil
+}
+
+// UpdateDict replaces the name, description and items of a Dict.
+// A non-zero d.Version must equal the stored version, otherwise ErrDictVersionConflict is returned.
+func (uc *DictUsecase) UpdateDict(ctx context.Context, d *Dict) (updated *Dict, err error) {
+	if err := checkDictItems(d.Items); err != nil {
+		return nil, err
+	}
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		old, err := uc.repo.FindByCode(ctx, d.Code)
+		if err != nil {
+			return err
+		}
+		if d.Version != 0 && d.Version != old.Version {
+			return ErrDictVersionConflict
+		}
+		updated = &Dict{
+			Code:        d.Code,
+			Name:        d.Name,
+			Description: d.Description,
+			Items:       d.Items,
+			Version:     old.Version,
+			CreatedAt:   old.CreatedAt,
+			UpdatedAt:   time.Now(),
+		}
+		if err := uc.repo.Update(ctx, updated); err != nil {
+			return err
+		}
+		updated.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, uc.saveError(err)
+	}
+	uc.publish(ctx, updated.Code, notify.ActionUpdate, updated.Version)
+	return updated, nil
+}
+
+// DeleteDict deletes a Dict and its items.
+func (uc *DictUsecase) DeleteDict(ctx context.Context, code string) error {
+	if err := uc.repo.Delete(ctx, code); err != nil {
+		return uc.saveError(err)
+	}
+	uc.publish(ctx, code, notify.ActionDelete, 0)
+	return nil
+}
+
+// WatchDict calls fn with the code of every Dict changed on any instance, until cancel is called.
+// It is a hook for code that keeps Dicts in process memory, nothing in the template subscribes:
+// reads go through the repo, whose cache is shared in Redis and invalidated on write.
+func (uc *DictUsecase) WatchDict(fn func(code string)) (cancel func()) {
+	return uc.notifier.Subscribe(TopicDict, func(e notify.Event) { fn(e.Key) })
+}
+
+func (uc *DictUsecase) publish(ctx context.Context, code, action string, version int64) {
+	if err := uc.notifier.Publish(ctx, notify.Event{Topic: TopicDict, Key: code, Action: action, Version: version}); err != nil {
+		uc.log.WithContext(ctx).Warnf("publish dict %s %s: %v", action, code, err)
+	}
+}
+
+// checkDictItems rejects items with the same value, and sorts them in the order repos return them.
+func checkDictItems(items []*DictItem) error {
+	seen := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.Value]; ok {
+			return dict.ErrorDictItemDuplicate("duplicate dict item value %q", item.Value)
+		}
+		seen[item.Value] = struct{}{}
+	}
+	slices.SortFunc(items, func(a, b *DictItem) int {
+		return cmp.Or(cmp.Compare(a.Sort, b.Sort), strings.Compare(a.Value, b.Value))
+	})
+	return nil
+}
+
+func (uc *DictUsecase) saveError(err error) error {
+	if IsDictNotFound(err) || dict.IsDictAlreadyExists(err) || dict.IsDictVersionConflict(err) {
+		return err
+	}
+	return dict.ErrorSaveDictFailed("save dict: %v", err).WithCause(err)
+}
@@ -0,0 +1,298 @@
+package biz
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/file"
+	"{{cookiecutter.project_name}}/pkg/query"
+	"{{cookiecutter.project_name}}/pkg/storage"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// sniffLen is the number of bytes http.DetectContentType looks at.
+const sniffLen = 512
+
+var (
+	// ErrFileNotFound is file not found, or not visible to the request.
+	ErrFileNotFound = file.ErrorFileNotFound("file not found")
+	// IsFileNotFound reports whether err is ErrFileNotFound.
+	IsFileNotFound = file.IsFileNotFound
+	// ErrFileTooLarge is the file exceeds FileConfig.MaxSize.
+	ErrFileTooLarge = file.ErrorFileTooLarge("file is too large")
+	// ErrDownloadURLInvalid is the signature of a download URL is wrong or expired.
+	ErrDownloadURLInvalid = file.ErrorDownloadUrlInvalid("download url is invalid or expired")
+
+	extPattern = regexp.MustCompile(`^\.[a-z0-9]{1,16}$`)
+)
+
+// File is the metadata of an uploaded file, the content is kept in the FileStorage under Key.
+type File struct {
+	ID          int64
+	Name        string
+	Key         string
+	Size        int64
+	ContentType string
+	// SHA256 is the hex checksum of the content.
+	SHA256 string
+	// Owner is the user who uploaded the file, empty for anonymous uploads.
+	Owner     string
+	CreatedAt time.Time
+}
+
+// FileUpload is a file being uploaded.
+type FileUpload struct {
+	Name string
+	// SHA256 is the expected hex checksum, empty to skip the check.
+	SHA256 string
+	Body   io.Reader
+}
+
+// FileOwner is the user of a request, Admin can access the Files of every user.
+type FileOwner struct {
+	User  string
+	Admin bool
+}
+
+func (o FileOwner) owns(f *File) bool {
+	return o.Admin || f.Owner == o.User
+}
+
+// FileRepo is a File repo.
+type FileRepo interface {
+	Create(context.Context, *File) error
+	// FindByID returns ErrFileNotFound if the File does not exist.
+	FindByID(context.Context, int64) (*File, error)
+	// List returns a page of the Files of owner, an invalid page returns InvalidQuery.
+	List(ctx context.Context, owner string, page query.PageRequest) (*query.Page[*File], error)
+	// Delete returns ErrFileNotFound if the File does not exist.
+	Delete(context.Context, int64) error
+}
+
+// FileStorage stores the content of Files.
+type FileStorage interface {
+	// Put stores r under key, size is -1 when unknown. Nothing is kept if r returns an error.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns storage.ErrNotExist if key does not exist.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL that downloads key as filename until expire.
+	PresignGet(ctx context.Context, key, filename string, expire time.Duration) (string, error)
+}
+
+// SignedFileStorage is a FileStorage whose presigned URLs are served by this application.
+type SignedFileStorage interface {
+	FileStorage
+	// Verify checks the query of a presigned URL of key.
+	Verify(key string, q url.Values) error
+}
+
+// FileConfig is the upload limits.
+type FileConfig struct {
+	MaxSize int64
+	// AllowTypes are the allowed detected types such as "image/png" or "image/*", empty allows all types.
+	AllowTypes    []string
+	PresignExpire time.Duration
+	// UploadTimeout bounds an upload instead of the server timeout.
+	UploadTimeout time.Duration
+}
+
+// FileUsecase is a File usecase.
+type FileUsecase struct {
+	repo    FileRepo
+	storage FileStorage
+	ids     IDGenerator
+	conf    *FileConfig
+	log     *log.Helper
+}
+
+// NewFileUsecase new a File usecase.
+func NewFileUsecase(repo FileRepo, storage FileStorage, ids IDGenerator, conf *FileConfig, logger log.Logger) *FileUsecase {
+	return &FileUsecase{repo: repo, storage: storage, ids: ids, conf: conf, log: log.NewHelper(logger)}
+}
+
+// Upload streams up.Body to the storage and saves its metadata.
+// The type is detected from the first bytes of the content, checked against FileConfig.AllowTypes and stored,
+// the type declared by the client and the extension of the name are not trusted,
+// the size is checked while streaming, so a file over the limit is rejected without being buffered.
+func (uc *FileUsecase) Upload(ctx context.Context, owner string, up *FileUpload) (*File, error) {
+	br := bufio.NewReaderSize(up.Body, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, file.ErrorUploadFileFailed("read file: %v", err).WithCause(err)
+	}
+	if len(head) == 0 {
+		return nil, file.ErrorFileMissing("file is empty")
+	}
+	detected, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !uc.allowed(detected) {
+		return nil, file.ErrorFileTypeNotAllowed("file type %s is not allowed", detected)
+	}
+
+	// The stored and served type is the detected one, the extension of the name is kept in the key
+	// only when its type is the same, so that a text file named x.html is not served as HTML.
+	ext := strings.ToLower(path.Ext(up.Name))
+	if extType, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext)); !extPattern.MatchString(ext) || extType != detected {
+		ext = ""
+	}
+
+	id, err := uc.ids.NextID()
+	if err != nil {
+		return nil, file.ErrorUploadFileFailed("generate id: %v", err).WithCause(err)
+	}
+	now := time.Now()
+	f := &File{
+		ID:          id.Int64(),
+		Name:        path.Base(strings.ReplaceAll(up.Name, "\\", "/")),
+		Key:         fmt.Sprintf("%s/%d%s", now.Format("2006/01/02"), id.Int64(), ext),
+		ContentType: detected,
+		Owner:       owner,
+		CreatedAt:   now,
+	}
+
+	h := sha256.New()
+	body := &limitedReader{r: br, limit: uc.conf.MaxSize}
+	if err := uc.storage.Put(ctx, f.Key, io.TeeReader(body, h), -1, detected); err != nil {
+		if body.exceeded {
+			return nil, ErrFileTooLarge
+		}
+		return nil, file.ErrorUploadFileFailed("store file: %v", err).WithCause(err)
+	}
+	f.Size = body.read
+	f.SHA256 = hex.EncodeToString(h.Sum(nil))
+	if up.SHA256 != "" && !strings.EqualFold(up.SHA256, f.SHA256) {
+		uc.deleteContent(ctx, f.Key)
+		return nil, file.ErrorFileChecksumMismatch("file checksum is %s, expected %s", f.SHA256, up.SHA256)
+	}
+	if err := uc.repo.Create(ctx, f); err != nil {
+		uc.deleteContent(ctx, f.Key)
+		return nil, file.ErrorUploadFileFailed("save file: %v", err).WithCause(err)
+	}
+	return f, nil
+}
+
+// GetFile gets a File visible to o.
+func (uc *FileUsecase) GetFile(ctx context.Context, o FileOwner, id int64) (*File, error) {
+	f, err := uc.repo.FindByID(ctx, id)
+	if err != nil {
+		if IsFileNotFound(err) {
+			return nil, err
+		}
+		return nil, file.ErrorGetFileFailed("get file: %v", err).WithCause(err)
+	}
+	if !o.owns(f) {
+		return nil, ErrFileNotFound
+	}
+	return f, nil
+}
+
+// ListFile lists a page of the Files uploaded by owner.
+func (uc *FileUsecase) ListFile(ctx context.Context, owner string, page query.PageRequest) (*query.Page[*File], error) {
+	result, err := uc.repo.List(ctx, owner, page)
+	if err != nil && !IsInvalidQuery(err) {
+		return nil, file.ErrorGetFileFailed("list file: %v", err).WithCause(err)
+	}
+	return result, err
+}
+
+// DownloadURL returns a presigned download URL of a File visible to o and its expiry time.
+func (uc *FileUsecase) DownloadURL(ctx context.Context, o FileOwner, id int64) (string, time.Time, error) {
+	f, err := uc.GetFile(ctx, o, id)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(uc.conf.PresignExpire)
+	u, err := uc.storage.PresignGet(ctx, f.Key, f.Name, uc.conf.PresignExpire)
+	if err != nil {
+		return "", time.Time{}, file.ErrorGetFileFailed("presign file: %v", err).WithCause(err)
+	}
+	return u, expiresAt, nil
+}
+
+// OpenSigned opens the content of key for a presigned URL served by this application, q is the query of the URL.
+func (uc *FileUsecase) OpenSigned(ctx context.Context, key string, q url.Values) (io.ReadCloser, error) {
+	s, ok := uc.storage.(SignedFileStorage)
+	if !ok {
+		return nil, ErrDownloadURLInvalid
+	}
+	if err := s.Verify(key, q); err != nil {
+		return nil, ErrDownloadURLInvalid
+	}
+	rc, err := s.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return nil, ErrFileNotFound
+		}
+		return nil, file.ErrorOpenFileFailed("open file: %v", err).WithCause(err)
+	}
+	return rc, nil
+}
+
+// DeleteFile deletes a File visible to o and its content.
+func (uc *FileUsecase) DeleteFile(ctx context.Context, o FileOwner, id int64) error {
+	f, err := uc.GetFile(ctx, o, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		if IsFileNotFound(err) {
+			return err
+		}
+		return file.ErrorDeleteFileFailed("delete file: %v", err).WithCause(err)
+	}
+	uc.deleteContent(ctx, f.Key)
+	return nil
+}
+
+// deleteContent removes stored content that has no metadata, a failure only leaves an orphan object.
+func (uc *FileUsecase) deleteContent(ctx context.Context, key string) {
+	if err := uc.storage.Delete(ctx, key); err != nil {
+		uc.log.WithContext(ctx).Warnf("delete file content %s: %v", key, err)
+	}
+}
+
+func (uc *FileUsecase) allowed(contentType string) bool {
+	if len(uc.conf.AllowTypes) == 0 {
+		return true
+	}
+	for _, t := range uc.conf.AllowTypes {
+		if t == contentType || strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedReader fails with exceeded set once more than limit bytes are read.
+type limitedReader struct {
+	r        io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if left := l.limit - l.read + 1; int64(len(p)) > left {
+		p = p[:left]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		l.exceeded = true
+		return 0, ErrFileTooLarge
+	}
+	return n, err
+}
@@ -2,21 +2,38 @@ package biz
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	v1 "{{cookiecutter.project_name}}/api/v1/helloworld"
+	"{{cookiecutter.project_name}}/pkg/i18n"
+	"{{cookiecutter.project_name}}/pkg/query"
 
-	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 )
 
 var (
 	// ErrUserNotFound is user not found.
-	ErrUserNotFound = errors.NotFound(v1.ErrorReason_USER_NOT_FOUND.String(), "user not found")
+	ErrUserNotFound = v1.ErrorUserNotFound("user not found")
+	// IsUserNotFound reports whether err is ErrUserNotFound.
+	IsUserNotFound = v1.IsUserNotFound
+	// IsInvalidQuery reports whether err is returned by InvalidQuery.
+	IsInvalidQuery = v1.IsInvalidQuery
 )
 
+// InvalidQuery wraps an invalid paging, filtering, sorting or field mask parameter.
+func InvalidQuery(err error) error {
+	return v1.ErrorInvalidQuery("invalid query: %v", err).WithMetadata(map[string]string{i18n.DetailKey: err.Error()})
+}
+
 // Greeter is a Greeter model.
 type Greeter struct {
-	Hello string
+	ID       int64
+	Hello    string
+	Age      *uint32
+	Order    uint32
+	Hobby    []string
+	Birthday time.Time
 }
 
 // GreeterRepo is a Greater repo.
@@ -24,23 +41,85 @@ type GreeterRepo interface {
 	Save(context.Context, *Greeter) (*Greeter, error)
 	Update(context.Context, *Greeter) (*Greeter, error)
 	FindByID(context.Context, int64) (*Greeter, error)
-	ListByHello(context.Context, string) ([]*Greeter, error)
-	ListAll(context.Context) ([]*Greeter, error)
+	// List returns a page of Greeters, an invalid page returns InvalidQuery.
+	List(context.Context, query.PageRequest) (*query.Page[*Greeter], error)
 }
 
 // GreeterUsecase is a Greeter usecase.
 type GreeterUsecase struct {
 	repo GreeterRepo
+	tx   Transaction
+	ids  IDGenerator
 	log  *log.Helper
 }
 
 // NewGreeterUsecase new a Greeter usecase.
-func NewGreeterUsecase(repo GreeterRepo, logger log.Logger) *GreeterUsecase {
-	return &GreeterUsecase{repo: repo, log: log.NewHelper(logger)}
+func NewGreeterUsecase(repo GreeterRepo, tx Transaction, ids IDGenerator, logger log.Logger) *GreeterUsecase {
+	return &GreeterUsecase{repo: repo, tx: tx, ids: ids, log: log.NewHelper(logger)}
 }
 
-// CreateGreeter creates a Greeter, and returns the new Greeter.
-func (uc *GreeterUsecase) CreateGreeter(ctx context.Context, g *Greeter) (*Greeter, error) {
+// SayHello returns the Greeter that greets name. It does not persist anything, so the
+// public greeting cannot be used to add users.
+func (uc *GreeterUsecase) SayHello(ctx context.Context, name string) *Greeter {
+	uc.log.WithContext(ctx).Debugf("SayHello: %v", name)
+	return &Greeter{Hello: name}
+}
+
+// CreateGreeter creates a Greeter with a new ID, and returns the new Greeter.
+// Writes related to the new Greeter should be added inside the same InTx.
+func (uc *GreeterUsecase) CreateGreeter(ctx context.Context, g *Greeter) (saved *Greeter, err error) {
 	uc.log.WithContext(ctx).Infof("CreateGreeter: %v", g.Hello)
-	return uc.repo.Save(ctx, g)
+	id, err := uc.ids.NextID()
+	if err != nil {
+		return nil, err
+	}
+	g = &Greeter{ID: id.Int64(), Hello: g.Hello, Age: g.Age, Order: g.Order, Hobby: g.Hobby, Birthday: g.Birthday}
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		saved, err = uc.repo.Save(ctx, g)
+		return err
+	})
+	return saved, err
+}
+
+// GetGreeter gets a Greeter by id, returns ErrUserNotFound if it does not exist.
+func (uc *GreeterUsecase) GetGreeter(ctx context.Context, id int64) (*Greeter, error) {
+	return uc.repo.FindByID(ctx, id)
+}
+
+// ListGreeter lists a page of Greeters.
+func (uc *GreeterUsecase) ListGreeter(ctx context.Context, page query.PageRequest) (*query.Page[*Greeter], error) {
+	return uc.repo.List(ctx, page)
+}
+
+// UpdateGreeter updates the fields of a Greeter named by paths, all fields when paths is empty.
+// Paths use the API field names: username, age, order, hobby and birthday.
+func (uc *GreeterUsecase) UpdateGreeter(ctx context.Context, g *Greeter, paths []string) (updated *Greeter, err error) {
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		merged, err := uc.repo.FindByID(ctx, g.ID)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			merged = g
+		}
+		for _, p := range paths {
+			switch p {
+			case "username":
+				merged.Hello = g.Hello
+			case "age":
+				merged.Age = g.Age
+			case "order":
+				merged.Order = g.Order
+			case "hobby":
+				merged.Hobby = g.Hobby
+			case "birthday":
+				merged.Birthday = g.Birthday
+			default:
+				return InvalidQuery(fmt.Errorf("field %q can not be updated", p))
+			}
+		}
+		updated, err = uc.repo.Update(ctx, merged)
+		return err
+	})
+	return updated, err
 }
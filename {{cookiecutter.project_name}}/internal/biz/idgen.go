@@ -0,0 +1,8 @@
+package biz
+
+import "{{cookiecutter.project_name}}/pkg/idgen"
+
+// IDGenerator generates unique, time-ordered IDs for new entities.
+type IDGenerator interface {
+	NextID() (idgen.ID, error)
+}
@@ -0,0 +1,14 @@
+package biz
+
+import (
+	"context"
+
+	"{{cookiecutter.project_name}}/pkg/notify"
+)
+
+// Notifier publishes change events to the subscribers on every instance.
+type Notifier interface {
+	Publish(context.Context, notify.Event) error
+	// Subscribe registers fn for the events of topic and returns a function that unregisters it.
+	Subscribe(topic string, fn func(notify.Event)) (cancel func())
+}
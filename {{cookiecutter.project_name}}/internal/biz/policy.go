@@ -0,0 +1,134 @@
+package biz
+
+import (
+	"context"
+
+	"{{cookiecutter.project_name}}/api/v1/admin"
+	"{{cookiecutter.project_name}}/pkg/query"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	// PolicyTypeRule grants Subject the Action on Object.
+	PolicyTypeRule = "p"
+	// PolicyTypeRole assigns Subject to the role named by Object.
+	PolicyTypeRole = "g"
+)
+
+var (
+	// ErrPolicyNotFound is policy not found.
+	ErrPolicyNotFound = admin.ErrorPolicyNotFound("policy not found")
+	// ErrPolicyAlreadyExists is policy already exists.
+	ErrPolicyAlreadyExists = admin.ErrorPolicyAlreadyExists("policy already exists")
+)
+
+// Policy is an authorization policy.
+type Policy struct {
+	Ptype   string
+	Subject string
+	Object  string
+	Action  string
+}
+
+// PolicyRepo is a Policy repo, it applies changes to the running authorizer.
+type PolicyRepo interface {
+	// List returns a page of Policies, an invalid page returns InvalidQuery.
+	List(context.Context, query.PageRequest) (*query.Page[*Policy], error)
+	// Add reports false if the Policy already exists.
+	Add(context.Context, *Policy) (bool, error)
+	// Update reports false if old does not exist.
+	Update(ctx context.Context, old, p *Policy) (bool, error)
+	// Delete reports false if the Policy does not exist.
+	Delete(context.Context, *Policy) (bool, error)
+	// Reload reloads all Policies from storage and returns their count.
+	Reload(context.Context) (int64, error)
+}
+
+// PolicyUsecase is a Policy usecase.
+type PolicyUsecase struct {
+	repo PolicyRepo
+	log  *log.Helper
+}
+
+// NewPolicyUsecase new a Policy usecase.
+func NewPolicyUsecase(repo PolicyRepo, logger log.Logger) *PolicyUsecase {
+	return &PolicyUsecase{repo: repo, log: log.NewHelper(logger)}
+}
+
+// ListPolicy lists a page of Policies.
+func (uc *PolicyUsecase) ListPolicy(ctx context.Context, page query.PageRequest) (*query.Page[*Policy], error) {
+	rv, err := uc.repo.List(ctx, page)
+	if err != nil && !IsInvalidQuery(err) {
+		return nil, admin.ErrorPolicyListFailed("list policies: %v", err).WithCause(err)
+	}
+	return rv, err
+}
+
+// AddPolicy adds a Policy, returns ErrPolicyAlreadyExists if it exists.
+func (uc *PolicyUsecase) AddPolicy(ctx context.Context, p *Policy) (*Policy, error) {
+	p = normalizePolicy(p)
+	uc.log.WithContext(ctx).Infof("AddPolicy: %v", *p)
+	ok, err := uc.repo.Add(ctx, p)
+	if err != nil {
+		return nil, admin.ErrorPolicyAddFailed("add policy: %v", err).WithCause(err)
+	}
+	if !ok {
+		return nil, ErrPolicyAlreadyExists
+	}
+	return p, nil
+}
+
+// UpdatePolicy replaces old with p, returns ErrPolicyNotFound if old does not exist.
+func (uc *PolicyUsecase) UpdatePolicy(ctx context.Context, old, p *Policy) (*Policy, error) {
+	old, p = normalizePolicy(old), normalizePolicy(p)
+	if old.Ptype != p.Ptype {
+		return nil, admin.ErrorPolicyUpdateFailed("ptype can not be changed")
+	}
+	uc.log.WithContext(ctx).Infof("UpdatePolicy: %v -> %v", *old, *p)
+	ok, err := uc.repo.Update(ctx, old, p)
+	if err != nil {
+		return nil, admin.ErrorPolicyUpdateFailed("update policy: %v", err).WithCause(err)
+	}
+	if !ok {
+		return nil, ErrPolicyNotFound
+	}
+	return p, nil
+}
+
+// DeletePolicy deletes a Policy, returns ErrPolicyNotFound if it does not exist.
+func (uc *PolicyUsecase) DeletePolicy(ctx context.Context, p *Policy) error {
+	p = normalizePolicy(p)
+	uc.log.WithContext(ctx).Infof("DeletePolicy: %v", *p)
+	ok, err := uc.repo.Delete(ctx, p)
+	if err != nil {
+		return admin.ErrorPolicyDeleteFailed("delete policy: %v", err).WithCause(err)
+	}
+	if !ok {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+// ReloadPolicy reloads all Policies from storage and returns their count.
+func (uc *PolicyUsecase) ReloadPolicy(ctx context.Context) (int64, error) {
+	n, err := uc.repo.Reload(ctx)
+	if err != nil {
+		return 0, admin.ErrorPolicyListFailed("reload policies: %v", err).WithCause(err)
+	}
+	return n, nil
+}
+
+// normalizePolicy clears the Action of a role assignment and defaults an empty Action to any method.
+func normalizePolicy(p *Policy) *Policy {
+	c := *p
+	switch c.Ptype {
+	case PolicyTypeRole:
+		c.Action = ""
+	case PolicyTypeRule:
+		if c.Action == "" {
+			c.Action = "*"
+		}
+	}
+	return &c
+}
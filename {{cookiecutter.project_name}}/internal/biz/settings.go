@@ -0,0 +1,171 @@
+package biz
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/settings"
+	"{{cookiecutter.project_name}}/pkg/notify"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Settings scopes, from the widest to the narrowest.
+const (
+	SettingsScopeGlobal = "global"
+	SettingsScopeTenant = "tenant"
+	SettingsScopeUser   = "user"
+)
+
+// TopicSettings is the Notifier topic of Settings changes, the event key is "scope:owner:name".
+const TopicSettings = "settings"
+
+var (
+	// ErrSettingsVersionConflict is the settings were changed by another request.
+	ErrSettingsVersionConflict = settings.ErrorSettingsVersionConflict("settings were modified by another request")
+)
+
+// Settings is a named JSON document stored for a scope and its owner.
+type Settings struct {
+	Scope string
+	// Owner is the tenant or user id, empty for the global scope.
+	Owner     string
+	Name      string
+	Value     map[string]any
+	Version   int64
+	UpdatedAt time.Time
+}
+
+// SettingsOwner is the tenant and user of a request, empty when unknown.
+type SettingsOwner struct {
+	Tenant string
+	User   string
+}
+
+// owner returns the owner of scope, false if the request has none.
+func (o SettingsOwner) owner(scope string) (string, bool) {
+	switch scope {
+	case SettingsScopeGlobal:
+		return "", true
+	case SettingsScopeTenant:
+		return o.Tenant, o.Tenant != ""
+	case SettingsScopeUser:
+		return o.User, o.User != ""
+	}
+	return "", false
+}
+
+// SettingsRepo is a Settings repo.
+type SettingsRepo interface {
+	// Find returns nil if the Settings do not exist.
+	Find(ctx context.Context, scope, owner, name string) (*Settings, error)
+	// Save creates or replaces the Settings and increments the stored version.
+	// It returns ErrSettingsVersionConflict if s.Version is not zero and not the stored version.
+	Save(ctx context.Context, s *Settings) (*Settings, error)
+	Delete(ctx context.Context, scope, owner, name string) error
+}
+
+// SettingsUsecase is a Settings usecase.
+type SettingsUsecase struct {
+	repo     SettingsRepo
+	notifier Notifier
+	log      *log.Helper
+}
+
+// NewSettingsUsecase new a Settings usecase.
+func NewSettingsUsecase(repo SettingsRepo, notifier Notifier, logger log.Logger) *SettingsUsecase {
+	return &SettingsUsecase{repo: repo, notifier: notifier, log: log.NewHelper(logger)}
+}
+
+// GetSettings gets the Settings of scope, or an empty document with version 0 if they do not exist.
+// An empty scope merges the top-level keys of the global, tenant and user Settings in that order,
+// the merged version is the sum of their versions.
+func (uc *SettingsUsecase) GetSettings(ctx context.Context, o SettingsOwner, scope, name string) (*Settings, error) {
+	if scope != "" {
+		owner, ok := o.owner(scope)
+		if !ok {
+			return nil, settings.ErrorSettingsScopeDenied("settings scope %s is not available", scope)
+		}
+		s, err := uc.repo.Find(ctx, scope, owner, name)
+		if err != nil {
+			return nil, settings.ErrorGetSettingsFailed("get settings: %v", err).WithCause(err)
+		}
+		if s == nil {
+			s = &Settings{Scope: scope, Owner: owner, Name: name, Value: map[string]any{}}
+		}
+		return s, nil
+	}
+
+	merged := &Settings{Name: name, Value: map[string]any{}}
+	for _, scope := range []string{SettingsScopeGlobal, SettingsScopeTenant, SettingsScopeUser} {
+		owner, ok := o.owner(scope)
+		if !ok {
+			continue
+		}
+		s, err := uc.repo.Find(ctx, scope, owner, name)
+		if err != nil {
+			return nil, settings.ErrorGetSettingsFailed("get settings: %v", err).WithCause(err)
+		}
+		if s == nil {
+			continue
+		}
+		for k, v := range s.Value {
+			merged.Value[k] = v
+		}
+		merged.Version += s.Version
+		if s.UpdatedAt.After(merged.UpdatedAt) {
+			merged.UpdatedAt = s.UpdatedAt
+		}
+	}
+	return merged, nil
+}
+
+// UpdateSettings replaces the Settings of s.Scope, callers check that the request may write the scope.
+// A non-zero s.Version must equal the stored version, otherwise ErrSettingsVersionConflict is returned.
+func (uc *SettingsUsecase) UpdateSettings(ctx context.Context, o SettingsOwner, s *Settings) (*Settings, error) {
+	owner, ok := o.owner(s.Scope)
+	if !ok {
+		return nil, settings.ErrorSettingsScopeDenied("settings scope %s is not available", s.Scope)
+	}
+	saved, err := uc.repo.Save(ctx, &Settings{Scope: s.Scope, Owner: owner, Name: s.Name, Value: s.Value, Version: s.Version, UpdatedAt: time.Now()})
+	if err != nil {
+		if settings.IsSettingsVersionConflict(err) {
+			return nil, err
+		}
+		return nil, settings.ErrorUpdateSettingsFailed("update settings: %v", err).WithCause(err)
+	}
+	uc.publish(ctx, saved, notify.ActionUpdate)
+	return saved, nil
+}
+
+// DeleteSettings deletes the Settings of scope, it is not an error if they do not exist.
+func (uc *SettingsUsecase) DeleteSettings(ctx context.Context, o SettingsOwner, scope, name string) error {
+	owner, ok := o.owner(scope)
+	if !ok {
+		return settings.ErrorSettingsScopeDenied("settings scope %s is not available", scope)
+	}
+	if err := uc.repo.Delete(ctx, scope, owner, name); err != nil {
+		return settings.ErrorUpdateSettingsFailed("delete settings: %v", err).WithCause(err)
+	}
+	uc.publish(ctx, &Settings{Scope: scope, Owner: owner, Name: name}, notify.ActionDelete)
+	return nil
+}
+
+// WatchSettings calls fn for every Settings changed on any instance, until cancel is called.
+// Like WatchDict it is a hook for code that keeps Settings in process memory, nothing in the template subscribes.
+func (uc *SettingsUsecase) WatchSettings(fn func(scope, owner, name string)) (cancel func()) {
+	return uc.notifier.Subscribe(TopicSettings, func(e notify.Event) {
+		// names never contain a colon, owners may
+		scope, rest, _ := strings.Cut(e.Key, ":")
+		i := strings.LastIndexByte(rest, ':')
+		fn(scope, rest[:max(i, 0)], rest[i+1:])
+	})
+}
+
+func (uc *SettingsUsecase) publish(ctx context.Context, s *Settings, action string) {
+	key := s.Scope + ":" + s.Owner + ":" + s.Name
+	if err := uc.notifier.Publish(ctx, notify.Event{Topic: TopicSettings, Key: key, Action: action, Version: s.Version}); err != nil {
+		uc.log.WithContext(ctx).Warnf("publish settings %s %s: %v", action, key, err)
+	}
+}
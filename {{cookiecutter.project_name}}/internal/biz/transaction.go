@@ -0,0 +1,9 @@
+package biz
+
+import "context"
+
+// Transaction runs fn in a transaction shared by every repo called with the ctx passed to fn.
+// Nested InTx calls create savepoints; an error or panic in fn rolls back its own level.
+type Transaction interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
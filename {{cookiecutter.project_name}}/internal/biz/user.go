@@ -0,0 +1,375 @@
+package biz
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/user"
+	"{{cookiecutter.project_name}}/pkg/auth"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var (
+	// ErrLoginFailed is wrong username or password.
+	ErrLoginFailed = user.ErrorLoginFailed("username or password is incorrect")
+	// ErrAccountDisabled is the account is disabled.
+	ErrAccountDisabled = user.ErrorAccountDisabled("account is disabled")
+	// ErrUsernameTaken is the username is already registered.
+	ErrUsernameTaken = user.ErrorUsernameTaken("username is already taken")
+	// ErrAccountNotFound is account not found.
+	ErrAccountNotFound = user.ErrorAccountNotFound("account not found")
+	// ErrWrongPassword is the old password does not match.
+	ErrWrongPassword = user.ErrorWrongPassword("old password is incorrect")
+	// ErrRefreshTokenInvalid is the refresh token is unknown, used or expired.
+	ErrRefreshTokenInvalid = user.ErrorRefreshTokenInvalid("refresh token is invalid or expired")
+)
+
+// Account is a user account.
+type Account struct {
+	ID           int64
+	Username     string
+	PasswordHash string
+	Nickname     string
+	Email        string
+	Roles        []string
+	Disabled     bool
+	// PasswordChangedAt is zero if the password has never been changed.
+	PasswordChangedAt time.Time
+	// TokenVersion is increased by every password change, access tokens carrying an older version are rejected.
+	TokenVersion int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Session is a refresh token issued to an Account, only the SHA-256 of the token is stored.
+type Session struct {
+	ID        string
+	UserID    int64
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// Token is the token pair returned by Login and Refresh.
+type Token struct {
+	AccessToken   string
+	AccessExpire  time.Duration
+	RefreshToken  string
+	RefreshExpire time.Duration
+	Account       *Account
+}
+
+// UserRepo is an Account repo.
+type UserRepo interface {
+	Create(context.Context, *Account) error
+	// FindByID returns ErrAccountNotFound if the Account does not exist.
+	FindByID(context.Context, int64) (*Account, error)
+	// FindByUsername returns ErrAccountNotFound if the Account does not exist.
+	FindByUsername(context.Context, string) (*Account, error)
+	UpdateProfile(context.Context, *Account) error
+	// UpdatePassword replaces the hash without revoking tokens, used to upgrade the hash on login.
+	UpdatePassword(ctx context.Context, id int64, hash string) error
+	// ChangePassword replaces the hash, records changedAt and increases the TokenVersion.
+	ChangePassword(ctx context.Context, id int64, hash string, changedAt time.Time) error
+	// TokenVersion returns the TokenVersion of the Account, returns ErrAccountNotFound if it does not exist.
+	TokenVersion(ctx context.Context, id int64) (int64, error)
+	CreateSession(context.Context, *Session) error
+	// TakeSession deletes and returns a Session, returns ErrRefreshTokenInvalid if it does not exist.
+	// Only one of concurrent calls with the same id succeeds.
+	TakeSession(ctx context.Context, id string) (*Session, error)
+	// DeleteSession deletes a Session of the user, it is not an error if the Session does not exist.
+	DeleteSession(ctx context.Context, id string, userID int64) error
+	// DeleteSessions deletes all Sessions of the user.
+	DeleteSessions(ctx context.Context, userID int64) error
+}
+
+// PasswordHasher hashes and verifies passwords.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) (bool, error)
+	// NeedsRehash reports whether hash was created with another algorithm or parameters.
+	NeedsRehash(hash string) bool
+}
+
+// TokenIssuer signs access tokens for an Account.
+type TokenIssuer interface {
+	Issue(*Account) (token string, expire time.Duration, err error)
+}
+
+// UserConfig configures the UserUsecase.
+type UserConfig struct {
+	Enable        bool
+	RefreshExpire time.Duration
+	LoginCaptcha  bool
+	DefaultRoles  []string
+}
+
+// UserUsecase is an Account usecase.
+type UserUsecase struct {
+	repo    UserRepo
+	tx      Transaction
+	ids     IDGenerator
+	hasher  PasswordHasher
+	issuer  TokenIssuer
+	captcha *CaptchaUsecase
+	conf    *UserConfig
+	log     *log.Helper
+
+	// dummyHash is verified when the username does not exist, so that the response time does not reveal it.
+	dummyOnce sync.Once
+	dummyHash string
+}
+
+// NewTokenCheck returns an auth.Check that rejects access tokens issued before the last password change of the user,
+// that is tokens whose TokenVersion is older than the one of the Account. It is nil when the user module is disabled.
+// Tokens whose subject is not an Account pass.
+func NewTokenCheck(repo UserRepo, conf *UserConfig) auth.Check {
+	if !conf.Enable {
+		return nil
+	}
+	return func(ctx context.Context, claims *auth.Claims) error {
+		id, err := strconv.ParseInt(claims.Subject, 10, 64)
+		if err != nil {
+			return nil
+		}
+		version, err := repo.TokenVersion(ctx, id)
+		if user.IsAccountNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if claims.TokenVersion < version {
+			return auth.ErrTokenRevoked
+		}
+		return nil
+	}
+}
+
+// NewUserUsecase new an Account usecase.
+func NewUserUsecase(repo UserRepo, tx Transaction, ids IDGenerator, hasher PasswordHasher, issuer TokenIssuer,
+	captcha *CaptchaUsecase, conf *UserConfig, logger log.Logger) *UserUsecase {
+	return &UserUsecase{repo: repo, tx: tx, ids: ids, hasher: hasher, issuer: issuer, captcha: captcha, conf: conf, log: log.NewHelper(logger)}
+}
+
+// Register creates an Account with the default roles, returns ErrUsernameTaken if the username exists.
+func (uc *UserUsecase) Register(ctx context.Context, a *Account, password string) (*Account, error) {
+	hash, err := uc.hasher.Hash(password)
+	if err != nil {
+		return nil, user.ErrorRegisterFailed("hash password: %v", err).WithCause(err)
+	}
+	id, err := uc.ids.NextID()
+	if err != nil {
+		return nil, user.ErrorRegisterFailed("generate id: %v", err).WithCause(err)
+	}
+	now := time.Now()
+	a = &Account{
+		ID:           id.Int64(),
+		Username:     a.Username,
+		PasswordHash: hash,
+		Nickname:     a.Nickname,
+		Email:        a.Email,
+		Roles:        uc.conf.DefaultRoles,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if _, err := uc.repo.FindByUsername(ctx, a.Username); err == nil {
+			return ErrUsernameTaken
+		} else if !user.IsAccountNotFound(err) {
+			return err
+		}
+		return uc.repo.Create(ctx, a)
+	})
+	if err != nil {
+		if user.IsUsernameTaken(err) {
+			return nil, err
+		}
+		return nil, user.ErrorRegisterFailed("register: %v", err).WithCause(err)
+	}
+	uc.log.WithContext(ctx).Infof("Register: %s (%d)", a.Username, a.ID)
+	return a, nil
+}
+
+// Login checks the captcha when enabled and the credentials, then issues a Token.
+func (uc *UserUsecase) Login(ctx context.Context, username, password, captchaID, captcha string) (*Token, error) {
+	if uc.conf.LoginCaptcha {
+		if err := uc.captcha.Verify(ctx, captchaID, captcha); err != nil {
+			return nil, err
+		}
+	}
+	a, err := uc.repo.FindByUsername(ctx, username)
+	if err != nil {
+		if !user.IsAccountNotFound(err) {
+			return nil, user.ErrorLoginFailed("find account: %v", err).WithCause(err)
+		}
+		uc.dummyOnce.Do(func() { uc.dummyHash, _ = uc.hasher.Hash("dummy-password") })
+		_, _ = uc.hasher.Verify(password, uc.dummyHash)
+		return nil, ErrLoginFailed
+	}
+	ok, err := uc.hasher.Verify(password, a.PasswordHash)
+	if err != nil {
+		uc.log.WithContext(ctx).Errorf("Login: verify password of %d: %v", a.ID, err)
+		return nil, ErrLoginFailed
+	}
+	if !ok {
+		return nil, ErrLoginFailed
+	}
+	if a.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	if uc.hasher.NeedsRehash(a.PasswordHash) {
+		if hash, err := uc.hasher.Hash(password); err == nil {
+			if err := uc.repo.UpdatePassword(ctx, a.ID, hash); err != nil {
+				uc.log.WithContext(ctx).Warnf("Login: rehash password of %d: %v", a.ID, err)
+			}
+		}
+	}
+	t, err := uc.issue(ctx, a)
+	if err != nil {
+		return nil, user.ErrorLoginFailed("issue token: %v", err).WithCause(err)
+	}
+	return t, nil
+}
+
+// Refresh exchanges a refresh token for a new Token, the refresh token can only be used once.
+func (uc *UserUsecase) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	var t *Token
+	err := uc.tx.InTx(ctx, func(ctx context.Context) error {
+		s, err := uc.repo.TakeSession(ctx, hashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if time.Now().After(s.ExpiresAt) {
+			return ErrRefreshTokenInvalid
+		}
+		a, err := uc.repo.FindByID(ctx, s.UserID)
+		if user.IsAccountNotFound(err) {
+			return ErrRefreshTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+		if a.Disabled {
+			return ErrAccountDisabled
+		}
+		t, err = uc.issue(ctx, a)
+		return err
+	})
+	if err != nil {
+		if user.IsRefreshTokenInvalid(err) || user.IsAccountDisabled(err) {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenInvalid.WithCause(err)
+	}
+	return t, nil
+}
+
+// Logout revokes a refresh token of the user.
+func (uc *UserUsecase) Logout(ctx context.Context, userID int64, refreshToken string) error {
+	return uc.repo.DeleteSession(ctx, hashRefreshToken(refreshToken), userID)
+}
+
+// GetProfile gets an Account by id, returns ErrAccountNotFound if it does not exist.
+func (uc *UserUsecase) GetProfile(ctx context.Context, id int64) (*Account, error) {
+	a, err := uc.repo.FindByID(ctx, id)
+	if err != nil && !user.IsAccountNotFound(err) {
+		return nil, user.ErrorGetUserInfoFailed("get profile: %v", err).WithCause(err)
+	}
+	return a, err
+}
+
+// UpdateProfile updates the profile fields named by paths, all fields when paths is empty.
+// Paths use the API field names: nickname and email.
+func (uc *UserUsecase) UpdateProfile(ctx context.Context, a *Account, paths []string) (updated *Account, err error) {
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		merged, err := uc.repo.FindByID(ctx, a.ID)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			paths = []string{"nickname", "email"}
+		}
+		for _, p := range paths {
+			switch p {
+			case "nickname":
+				merged.Nickname = a.Nickname
+			case "email":
+				merged.Email = a.Email
+			default:
+				return InvalidQuery(fmt.Errorf("field %q can not be updated", p))
+			}
+		}
+		merged.UpdatedAt = time.Now()
+		if err := uc.repo.UpdateProfile(ctx, merged); err != nil {
+			return err
+		}
+		updated = merged
+		return nil
+	})
+	if err != nil && !user.IsAccountNotFound(err) && !IsInvalidQuery(err) {
+		return nil, user.ErrorUpdateUserInfoFailed("update profile: %v", err).WithCause(err)
+	}
+	return updated, err
+}
+
+// ChangePassword checks the old password, saves the new one and revokes every refresh token of the user.
+// Access tokens issued before the change are rejected, see NewTokenCheck.
+func (uc *UserUsecase) ChangePassword(ctx context.Context, id int64, oldPassword, newPassword string) error {
+	hash, err := uc.hasher.Hash(newPassword)
+	if err != nil {
+		return user.ErrorUpdatePasswordFailed("hash password: %v", err).WithCause(err)
+	}
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		a, err := uc.repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		ok, err := uc.hasher.Verify(oldPassword, a.PasswordHash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrWrongPassword
+		}
+		if err := uc.repo.ChangePassword(ctx, id, hash, time.Now()); err != nil {
+			return err
+		}
+		return uc.repo.DeleteSessions(ctx, id)
+	})
+	if err != nil && !user.IsWrongPassword(err) {
+		return user.ErrorUpdatePasswordFailed("change password: %v", err).WithCause(err)
+	}
+	return err
+}
+
+// issue signs an access token and saves a new refresh token Session.
+func (uc *UserUsecase) issue(ctx context.Context, a *Account) (*Token, error) {
+	access, expire, err := uc.issuer.Issue(a)
+	if err != nil {
+		return nil, err
+	}
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(b[:])
+	now := time.Now()
+	s := &Session{ID: hashRefreshToken(refresh), UserID: a.ID, ExpiresAt: now.Add(uc.conf.RefreshExpire), CreatedAt: now}
+	if err := uc.repo.CreateSession(ctx, s); err != nil {
+		return nil, err
+	}
+	return &Token{AccessToken: access, AccessExpire: expire, RefreshToken: refresh, RefreshExpire: uc.conf.RefreshExpire, Account: a}, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
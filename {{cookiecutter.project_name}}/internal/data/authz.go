@@ -0,0 +1,164 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/authz"
+	"{{cookiecutter.project_name}}/pkg/query"
+	"time"
+
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// topicPolicy 策略变更事件的主题
+const topicPolicy = "authz:policy"
+
+// errAuthzDisabled 未启用 conf.Authz 时调用策略管理接口
+var errAuthzDisabled = errors.New("authz is not enabled")
+
+var policyFields = query.Fields{
+	"ptype":   "ptype",
+	"subject": "subject",
+	"object":  "object",
+	"action":  "action",
+}
+
+// NewAuthorizer 根据 conf.Authz 创建鉴权器，未启用时返回 nil
+// 配置数据库时策略保存在 casbin_rule 表，否则仅保存在内存中；配置 Redis 时通过 Notifier 同步其他实例
+func NewAuthorizer(c *conf.Config, data *Data, n biz.Notifier, logger log.Logger) (*authz.Authorizer, func(), error) {
+	ac := c.GetAuthz()
+	if !ac.GetEnable() {
+		return nil, func() {}, nil
+	}
+	if !c.GetAuth().GetEnable() {
+		return nil, nil, errors.New("authz: requires auth.enable")
+	}
+	helper := log.NewHelper(logger)
+	opts := []authz.Option{
+		authz.WithModelFile(ac.GetModelFile()),
+		authz.WithAdminRole(ac.GetAdminRole()),
+		authz.WithInitAdmins(ac.GetAdmins()...),
+		authz.WithLogger(logger),
+	}
+	for _, r := range ac.GetInitPolicies() {
+		opts = append(opts, authz.WithInitRules(authz.Rule{Ptype: r.GetPtype(), Subject: r.GetSubject(), Object: r.GetObject(), Action: r.GetAction()}))
+	}
+	if s := ac.GetReloadInterval(); s != "" {
+		interval, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid authz reloadInterval: %w", err)
+		}
+		opts = append(opts, authz.WithAutoLoad(interval))
+	}
+
+	var (
+		adapter persist.Adapter
+		watcher *authz.Watcher
+	)
+	if data.db != nil {
+		adapter = authz.NewAdapter(data.db)
+		// 未配置 Redis 时 Notifier 只通知本实例，无需同步
+		if data.rdb != nil {
+			watcher = authz.NewWatcher(n, topicPolicy)
+			opts = append(opts, authz.WithWatcher(watcher))
+		}
+	} else {
+		helper.Warn("authz: data.database is not configured, policies are kept in memory only")
+	}
+
+	a, err := authz.New(adapter, opts...)
+	if err != nil {
+		if watcher != nil {
+			watcher.Close()
+		}
+		return nil, nil, err
+	}
+	return a, a.Close, nil
+}
+
+type policyRepo struct {
+	az  *authz.Authorizer
+	log *log.Helper
+}
+
+// NewPolicyRepo .
+func NewPolicyRepo(az *authz.Authorizer, logger log.Logger) biz.PolicyRepo {
+	return &policyRepo{az: az, log: log.NewHelper(logger)}
+}
+
+func (r *policyRepo) List(ctx context.Context, page query.PageRequest) (*query.Page[*biz.Policy], error) {
+	q, err := query.Parse(page, policyFields, query.WithDefaultOrder("ptype, subject, object, action"))
+	if err != nil {
+		return nil, biz.InvalidQuery(err)
+	}
+	if r.az == nil {
+		return nil, errAuthzDisabled
+	}
+	rules, err := r.az.Rules()
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*biz.Policy, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, &biz.Policy{Ptype: rule.Ptype, Subject: rule.Subject, Object: rule.Object, Action: rule.Action})
+	}
+	return query.Apply(q, policies, policyField), nil
+}
+
+func (r *policyRepo) Add(ctx context.Context, p *biz.Policy) (bool, error) {
+	if r.az == nil {
+		return false, errAuthzDisabled
+	}
+	return r.az.Add(toRule(p))
+}
+
+func (r *policyRepo) Update(ctx context.Context, old, p *biz.Policy) (bool, error) {
+	if r.az == nil {
+		return false, errAuthzDisabled
+	}
+	return r.az.Update(toRule(old), toRule(p))
+}
+
+func (r *policyRepo) Delete(ctx context.Context, p *biz.Policy) (bool, error) {
+	if r.az == nil {
+		return false, errAuthzDisabled
+	}
+	return r.az.Remove(toRule(p))
+}
+
+func (r *policyRepo) Reload(ctx context.Context) (int64, error) {
+	if r.az == nil {
+		return 0, errAuthzDisabled
+	}
+	if err := r.az.Reload(); err != nil {
+		return 0, err
+	}
+	rules, err := r.az.Rules()
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(rules)), nil
+}
+
+// policyField 返回 API 字段对应的值，供内存过滤与排序使用
+func policyField(p *biz.Policy, field string) any {
+	switch field {
+	case "ptype":
+		return p.Ptype
+	case "subject":
+		return p.Subject
+	case "object":
+		return p.Object
+	case "action":
+		return p.Action
+	}
+	return nil
+}
+
+func toRule(p *biz.Policy) authz.Rule {
+	return authz.Rule{Ptype: p.Ptype, Subject: p.Subject, Object: p.Object, Action: p.Action}
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/captcha"
+	"time"
+)
+
+type captchaRepo struct {
+	gen *captcha.Generator
+}
+
+// NewCaptchaRepo 根据 conf.Captcha 创建验证码仓储，配置 Redis 时答案保存在 Redis 中
+func NewCaptchaRepo(c *conf.Config, data *Data) (biz.CaptchaRepo, error) {
+	cc := c.GetCaptcha()
+	var expire time.Duration
+	if s := cc.GetExpire(); s != "" {
+		var err error
+		if expire, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("invalid captcha expire: %w", err)
+		}
+	}
+	var store captcha.Store = captcha.NewMemoryStore(int(cc.GetMemoryLimit()))
+	if data.rdb != nil {
+		store = captcha.NewRedisStore(data.rdb, fmt.Sprintf("captcha:%s:", c.GetGlobal().GetAppName()))
+	}
+	gen, err := captcha.New(store,
+		captcha.WithType(cc.GetType()),
+		captcha.WithSize(int(cc.GetWidth()), int(cc.GetHeight())),
+		captcha.WithLength(int(cc.GetLength())),
+		captcha.WithExpire(expire),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &captchaRepo{gen: gen}, nil
+}
+
+func (r *captchaRepo) Generate(ctx context.Context) (*biz.Captcha, error) {
+	c, err := r.gen.Generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &biz.Captcha{ID: c.ID, Image: c.Image, Expire: c.Expire}, nil
+}
+
+func (r *captchaRepo) Verify(ctx context.Context, id, answer string) (bool, error) {
+	return r.gen.Verify(ctx, id, answer)
+}
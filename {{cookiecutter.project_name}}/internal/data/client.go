@@ -0,0 +1,102 @@
+package data
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/breaker"
+	"{{cookiecutter.project_name}}/pkg/nacos"
+	"{{cookiecutter.project_name}}/pkg/tlsconf"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	ggrpc "google.golang.org/grpc"
+)
+
+const defaultClientTimeout = 2 * time.Second
+
+// clientConfig 解析 conf.Client.Services[name]，返回超时、中间件与 TLS 配置，未启用 TLS 时 tls.Config 为 nil
+func clientConfig(c *conf.Config, name string, logger log.Logger) (*conf.Client_Service, time.Duration, []middleware.Middleware, *tls.Config, error) {
+	sc, ok := c.GetClient().GetServices()[name]
+	if !ok || sc.GetEndpoint() == "" {
+		return nil, 0, nil, nil, fmt.Errorf("client: endpoint of service %q is not configured", name)
+	}
+	timeout := defaultClientTimeout
+	if s := sc.GetTimeout(); s != "" {
+		var err error
+		if timeout, err = time.ParseDuration(s); err != nil {
+			return nil, 0, nil, nil, fmt.Errorf("client: invalid timeout of service %q: %w", name, err)
+		}
+	}
+	mws := []middleware.Middleware{recovery.Recovery(), logging.Client(logger)}
+	bc := sc.GetBreaker()
+	if bc == nil {
+		bc = c.GetClient().GetBreaker()
+	}
+	if bc.GetEnable() {
+		opts, err := breaker.Options(bc, c.GetClient().GetBreaker())
+		if err != nil {
+			return nil, 0, nil, nil, err
+		}
+		mws = append(mws, breaker.Client(name, opts...))
+	}
+	tc := sc.GetTls()
+	if tc == nil {
+		tc = c.GetClient().GetTls()
+	}
+	tlsConf, err := tlsconf.Client(tc, logger)
+	if err != nil {
+		return nil, 0, nil, nil, fmt.Errorf("client: tls of service %q: %w", name, err)
+	}
+	return sc, timeout, mws, tlsConf, nil
+}
+
+// NewGRPCClient 按 conf.Client.Services[name] 创建 gRPC 连接，启用熔断时按操作熔断，启用 TLS 时使用 TLS 连接
+// endpoint 为 discovery:///<服务名> 时通过 nacos 发现实例，nac 为 nil 时无法使用
+func NewGRPCClient(ctx context.Context, c *conf.Config, nac *nacos.Client, name string, logger log.Logger) (*ggrpc.ClientConn, error) {
+	sc, timeout, mws, tlsConf, err := clientConfig(c, name, logger)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.ClientOption{
+		grpc.WithEndpoint(sc.GetEndpoint()),
+		grpc.WithTimeout(timeout),
+		grpc.WithMiddleware(mws...),
+	}
+	if nac != nil {
+		opts = append(opts, grpc.WithDiscovery(nac))
+	}
+	if tlsConf != nil {
+		return grpc.Dial(ctx, append(opts, grpc.WithTLSConfig(tlsConf))...)
+	}
+	return grpc.DialInsecure(ctx, opts...)
+}
+
+// NewHTTPClient 按 conf.Client.Services[name] 创建 HTTP 客户端，配置同 NewGRPCClient
+func NewHTTPClient(ctx context.Context, c *conf.Config, nac *nacos.Client, name string, logger log.Logger) (*http.Client, error) {
+	sc, timeout, mws, tlsConf, err := clientConfig(c, name, logger)
+	if err != nil {
+		return nil, err
+	}
+	opts := []http.ClientOption{
+		http.WithEndpoint(sc.GetEndpoint()),
+		http.WithTimeout(timeout),
+		http.WithMiddleware(mws...),
+	}
+	if nac != nil {
+		opts = append(opts, http.WithDiscovery(nac))
+	}
+	if tlsConf != nil {
+		// kratos 会把 TLS 配置写入传入的 Transport，默认的 http.DefaultTransport 为全局共享，需使用独立的副本
+		opts = append(opts, http.WithTransport(nethttp.DefaultTransport.(*nethttp.Transport).Clone()), http.WithTLSConfig(tlsConf))
+	}
+	return http.NewClient(ctx, opts...)
+}
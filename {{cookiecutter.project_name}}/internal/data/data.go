@@ -1,24 +1,110 @@
 package data
 
 import (
+	"context"
 	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/cache"
+	"{{cookiecutter.project_name}}/pkg/database"
+	"{{cookiecutter.project_name}}/pkg/migrate"
+	"{{cookiecutter.project_name}}/pkg/ops"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/wire"
+	"github.com/redis/go-redis/v9"
 )
 
 // ProviderSet is data providers.
-var ProviderSet = wire.NewSet(NewData, NewGreeterRepo)
+var ProviderSet = wire.NewSet(NewData, NewTransaction, NewIDGenerator, NewAuthorizer, NewRateLimiter, NewGreeterRepo, NewPolicyRepo, NewCaptchaRepo,
+	NewUserRepo, NewPasswordHasher, NewTokenIssuer, NewUserConfig,
+	NewNotifier, NewDictRepo, NewSettingsRepo,
+	NewFileRepo, NewFileStorage, NewFileConfig, NewHealthChecks)
 
 // Data .
 type Data struct {
-	// TODO wrapped database client
+	// db 未配置 conf.Data.Database 时为 nil
+	db *database.DB
+	// rdb 未配置 conf.Data.Redis 时为 nil
+	rdb redis.UniversalClient
 }
 
 // NewData .
 func NewData(c *conf.Config, logger log.Logger) (*Data, func(), error) {
+	helper := log.NewHelper(logger)
+	d := &Data{}
 	cleanup := func() {
-		log.NewHelper(logger).Info("closing the data resources")
+		helper.Info("closing the data resources")
+		if d.db != nil {
+			if err := d.db.Close(); err != nil {
+				helper.Errorf("failed to close database: %v", err)
+			}
+		}
+		if d.rdb != nil {
+			if err := d.rdb.Close(); err != nil {
+				helper.Errorf("failed to close redis: %v", err)
+			}
+		}
 	}
-	return &Data{}, cleanup, nil
+
+	if dc := c.GetData().GetDatabase(); dc.GetDriver() != "" {
+		db, err := database.Open(dc, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		d.db = db
+		helper.Infof("database connected: %s", db.Dialect())
+		if c.GetData().GetAutoMigrate() {
+			if err := autoMigrate(db, logger); err != nil {
+				cleanup()
+				return nil, nil, err
+			}
+		}
+	}
+	if rc := c.GetData().GetRedis(); rc.GetAddr() != "" {
+		rdb, err := cache.NewRedis(rc)
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		d.rdb = rdb
+		helper.Infof("redis connected: %s", rc.GetAddr())
+	}
+	return d, cleanup, nil
+}
+
+// NewHealthChecks 管理端口 /readyz 使用的就绪检查，只包含已配置的数据库与 Redis
+func NewHealthChecks(d *Data) ops.Checks {
+	checks := ops.Checks{}
+	if d.db != nil {
+		checks["database"] = d.db.PingContext
+	}
+	if d.rdb != nil {
+		checks["redis"] = func(ctx context.Context) error {
+			return d.rdb.Ping(ctx).Err()
+		}
+	}
+	return checks
+}
+
+// autoMigrate 执行嵌入的迁移文件
+func autoMigrate(db *database.DB, logger log.Logger) error {
+	m, err := migrate.New(db, Migrations(), logger)
+	if err != nil {
+		return err
+	}
+	return m.Up(context.Background())
+}
+
+// Health 检查已配置的数据库与 Redis 是否可用
+func (d *Data) Health(ctx context.Context) error {
+	if d.db != nil {
+		if err := d.db.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	if d.rdb != nil {
+		if err := d.rdb.Ping(ctx).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
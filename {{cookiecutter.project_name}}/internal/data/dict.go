@@ -0,0 +1,286 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/cache"
+	"{{cookiecutter.project_name}}/pkg/database"
+	"{{cookiecutter.project_name}}/pkg/query"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const dictColumns = "code, name, description, version, created_at, updated_at"
+
+// dictFields 列表接口允许过滤与排序的字段
+var dictFields = query.Fields{
+	"code":      "code",
+	"name":      "name",
+	"updatedAt": "updated_at",
+}
+
+func parseDictQuery(page query.PageRequest) (*query.Query, error) {
+	q, err := query.Parse(page, dictFields, query.WithDefaultOrder("code"), query.WithTiebreak("code"))
+	if err != nil {
+		return nil, biz.InvalidQuery(err)
+	}
+	return q, nil
+}
+
+type dictRepo struct {
+	data  *Data
+	log   *log.Helper
+	cache *cache.Cache[*biz.Dict]
+}
+
+// NewDictRepo 未配置数据库时使用内存存储，配置 Redis 时缓存字典
+func NewDictRepo(data *Data, logger log.Logger) biz.DictRepo {
+	if data.db == nil {
+		return newDictMemoryRepo()
+	}
+	return &dictRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+		cache: cache.New[*biz.Dict](data.rdb, "dict",
+			cache.WithNegative(biz.ErrDictNotFound, biz.IsDictNotFound, 0)),
+	}
+}
+
+func (r *dictRepo) List(ctx context.Context, page query.PageRequest) (*query.Page[*biz.Dict], error) {
+	q, err := parseDictQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	where, args := q.Where()
+	var total int64
+	if err := r.data.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dict"+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+	rows, err := r.data.db.QueryContext(ctx,
+		"SELECT "+dictColumns+" FROM dict"+where+q.OrderBy()+" LIMIT ? OFFSET ?",
+		append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rv := &query.Page[*biz.Dict]{TotalSize: total, NextPageToken: q.NextPageToken(total)}
+	for rows.Next() {
+		var d biz.Dict
+		if err := rows.Scan(&d.Code, &d.Name, &d.Description, &d.Version, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rv.Items = append(rv.Items, &d)
+	}
+	return rv, rows.Err()
+}
+
+func (r *dictRepo) FindByCode(ctx context.Context, code string) (*biz.Dict, error) {
+	// 事务中可能读到未提交的数据，不经过缓存
+	if database.InTransaction(ctx) {
+		return r.findByCode(ctx, code)
+	}
+	return r.cache.Get(ctx, code, func(ctx context.Context) (*biz.Dict, error) {
+		return r.findByCode(ctx, code)
+	})
+}
+
+func (r *dictRepo) findByCode(ctx context.Context, code string) (*biz.Dict, error) {
+	var d biz.Dict
+	err := r.data.db.QueryRowContext(ctx, "SELECT "+dictColumns+" FROM dict WHERE code = ?", code).
+		Scan(&d.Code, &d.Name, &d.Description, &d.Version, &d.CreatedAt, &d.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, biz.ErrDictNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.data.db.QueryContext(ctx,
+		"SELECT value, label, sort_order, disabled, extra FROM dict_item WHERE dict_code = ? ORDER BY sort_order, value", code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	d.Items = []*biz.DictItem{}
+	for rows.Next() {
+		var item biz.DictItem
+		if err := rows.Scan(&item.Value, &item.Label, &item.Sort, &item.Disabled, &item.Extra); err != nil {
+			return nil, err
+		}
+		d.Items = append(d.Items, &item)
+	}
+	return &d, rows.Err()
+}
+
+func (r *dictRepo) Create(ctx context.Context, d *biz.Dict) error {
+	return r.data.db.InTx(ctx, func(ctx context.Context) error {
+		if _, err := r.data.db.ExecContext(ctx,
+			"INSERT INTO dict ("+dictColumns+") VALUES (?, ?, ?, ?, ?, ?)",
+			d.Code, d.Name, d.Description, d.Version, d.CreatedAt, d.UpdatedAt); err != nil {
+			return err
+		}
+		if err := r.insertItems(ctx, d); err != nil {
+			return err
+		}
+		// 清除创建前写入的负缓存
+		r.invalidate(ctx, d.Code)
+		return nil
+	})
+}
+
+func (r *dictRepo) Update(ctx context.Context, d *biz.Dict) error {
+	return r.data.db.InTx(ctx, func(ctx context.Context) error {
+		res, err := r.data.db.ExecContext(ctx,
+			"UPDATE dict SET name = ?, description = ?, version = version + 1, updated_at = ? WHERE code = ? AND version = ?",
+			d.Name, d.Description, d.UpdatedAt, d.Code, d.Version)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return biz.ErrDictVersionConflict
+		}
+		if _, err := r.data.db.ExecContext(ctx, "DELETE FROM dict_item WHERE dict_code = ?", d.Code); err != nil {
+			return err
+		}
+		if err := r.insertItems(ctx, d); err != nil {
+			return err
+		}
+		r.invalidate(ctx, d.Code)
+		return nil
+	})
+}
+
+func (r *dictRepo) Delete(ctx context.Context, code string) error {
+	return r.data.db.InTx(ctx, func(ctx context.Context) error {
+		res, err := r.data.db.ExecContext(ctx, "DELETE FROM dict WHERE code = ?", code)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return biz.ErrDictNotFound
+		}
+		if _, err := r.data.db.ExecContext(ctx, "DELETE FROM dict_item WHERE dict_code = ?", code); err != nil {
+			return err
+		}
+		r.invalidate(ctx, code)
+		return nil
+	})
+}
+
+func (r *dictRepo) insertItems(ctx context.Context, d *biz.Dict) error {
+	for _, item := range d.Items {
+		if _, err := r.data.db.ExecContext(ctx,
+			"INSERT INTO dict_item (dict_code, value, label, sort_order, disabled, extra) VALUES (?, ?, ?, ?, ?, ?)",
+			d.Code, item.Value, item.Label, item.Sort, item.Disabled, item.Extra); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidate 在事务提交后删除缓存
+func (r *dictRepo) invalidate(ctx context.Context, code string) {
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := r.cache.Delete(ctx, code); err != nil {
+			r.log.WithContext(ctx).Warnf("failed to invalidate dict cache: %v", err)
+		}
+	})
+}
+
+// dictMemoryRepo 未配置数据库时使用的内存存储
+type dictMemoryRepo struct {
+	mu    sync.RWMutex
+	dicts map[string]*biz.Dict
+}
+
+func newDictMemoryRepo() biz.DictRepo {
+	return &dictMemoryRepo{dicts: make(map[string]*biz.Dict)}
+}
+
+func (r *dictMemoryRepo) List(ctx context.Context, page query.PageRequest) (*query.Page[*biz.Dict], error) {
+	q, err := parseDictQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	all := make([]*biz.Dict, 0, len(r.dicts))
+	for _, d := range r.dicts {
+		c := *d
+		c.Items = nil
+		all = append(all, &c)
+	}
+	r.mu.RUnlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].Code < all[j].Code })
+	return query.Apply(q, all, dictField), nil
+}
+
+func (r *dictMemoryRepo) FindByCode(ctx context.Context, code string) (*biz.Dict, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.dicts[code]
+	if !ok {
+		return nil, biz.ErrDictNotFound
+	}
+	return cloneDict(d), nil
+}
+
+func (r *dictMemoryRepo) Create(ctx context.Context, d *biz.Dict) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.dicts[d.Code]; ok {
+		return biz.ErrDictAlreadyExists
+	}
+	r.dicts[d.Code] = cloneDict(d)
+	return nil
+}
+
+func (r *dictMemoryRepo) Update(ctx context.Context, d *biz.Dict) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.dicts[d.Code]
+	if !ok || old.Version != d.Version {
+		return biz.ErrDictVersionConflict
+	}
+	saved := cloneDict(d)
+	saved.Version++
+	r.dicts[d.Code] = saved
+	return nil
+}
+
+func (r *dictMemoryRepo) Delete(ctx context.Context, code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.dicts[code]; !ok {
+		return biz.ErrDictNotFound
+	}
+	delete(r.dicts, code)
+	return nil
+}
+
+// dictField 返回 API 字段对应的值，供内存过滤与排序使用
+func dictField(d *biz.Dict, field string) any {
+	switch field {
+	case "code":
+		return d.Code
+	case "name":
+		return d.Name
+	case "updatedAt":
+		return d.UpdatedAt
+	}
+	return nil
+}
+
+// cloneDict 复制字典，避免调用方修改存储中的数据
+func cloneDict(d *biz.Dict) *biz.Dict {
+	c := *d
+	c.Items = make([]*biz.DictItem, 0, len(d.Items))
+	for _, item := range d.Items {
+		i := *item
+		c.Items = append(c.Items, &i)
+	}
+	return &c
+}
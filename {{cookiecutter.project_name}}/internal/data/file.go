@@ -0,0 +1,191 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/query"
+	"{{cookiecutter.project_name}}/pkg/storage"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	fileColumns          = "id, name, object_key, size, content_type, sha256, owner, created_at"
+	defaultFileRoot      = "data/files"
+	defaultFileBaseURL   = "/api/v1/files/download"
+	defaultFileMaxSize   = 32 << 20
+	defaultPresignExpire = 15 * time.Minute
+	defaultUploadTimeout = 10 * time.Minute
+	storageTimeout       = 10 * time.Second
+)
+
+// errFileDisabled 未启用 conf.File 时调用文件接口
+var errFileDisabled = errors.New("file module is not enabled")
+
+// fileFields 列表接口允许过滤与排序的字段
+var fileFields = query.Fields{
+	"id":          "id",
+	"name":        "name",
+	"contentType": "content_type",
+	"createdAt":   "created_at",
+}
+
+type fileRepo struct {
+	data *Data
+	log  *log.Helper
+}
+
+// NewFileRepo 创建文件仓储，启用 conf.File 时要求配置数据库
+func NewFileRepo(c *conf.Config, data *Data, logger log.Logger) (biz.FileRepo, error) {
+	if c.GetFile().GetEnable() && data.db == nil {
+		return nil, errors.New("file: requires data.database")
+	}
+	return &fileRepo{data: data, log: log.NewHelper(logger)}, nil
+}
+
+// NewFileStorage 根据 conf.File.Storage 创建文件存储，未启用 conf.File 时返回 nil
+func NewFileStorage(c *conf.Config, logger log.Logger) (biz.FileStorage, error) {
+	fc := c.GetFile()
+	if !fc.GetEnable() {
+		return nil, nil
+	}
+	switch fc.GetStorage() {
+	case "", "local":
+		lc := fc.GetLocal()
+		root := lc.GetRoot()
+		if root == "" {
+			root = defaultFileRoot
+		}
+		baseURL := lc.GetBaseUrl()
+		if baseURL == "" {
+			baseURL = defaultFileBaseURL
+		}
+		opts := []storage.LocalOption{storage.WithBaseURL(baseURL)}
+		if lc.GetSecret() != "" {
+			opts = append(opts, storage.WithSecret([]byte(lc.GetSecret())))
+		} else {
+			log.NewHelper(logger).Warn("file: local.secret is not set, download urls become invalid after restart")
+		}
+		return storage.NewLocal(root, opts...)
+	case "s3":
+		ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+		defer cancel()
+		return storage.NewS3(ctx, fc.GetS3())
+	default:
+		return nil, fmt.Errorf("file: unknown storage %q", fc.GetStorage())
+	}
+}
+
+// NewFileConfig 解析 conf.File
+func NewFileConfig(c *conf.Config) (*biz.FileConfig, error) {
+	fc := c.GetFile()
+	bc := &biz.FileConfig{
+		MaxSize:       defaultFileMaxSize,
+		AllowTypes:    fc.GetAllowTypes(),
+		PresignExpire: defaultPresignExpire,
+		UploadTimeout: defaultUploadTimeout,
+	}
+	if fc.GetMaxSize() > 0 {
+		bc.MaxSize = fc.GetMaxSize()
+	}
+	if s := fc.GetPresignExpire(); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid file presignExpire %q", s)
+		}
+		bc.PresignExpire = d
+	}
+	if s := fc.GetUploadTimeout(); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid file uploadTimeout %q", s)
+		}
+		bc.UploadTimeout = d
+	}
+	return bc, nil
+}
+
+func (r *fileRepo) Create(ctx context.Context, f *biz.File) error {
+	if r.data.db == nil {
+		return errFileDisabled
+	}
+	_, err := r.data.db.ExecContext(ctx,
+		"INSERT INTO file_object ("+fileColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		f.ID, f.Name, f.Key, f.Size, f.ContentType, f.SHA256, f.Owner, f.CreatedAt)
+	return err
+}
+
+func (r *fileRepo) FindByID(ctx context.Context, id int64) (*biz.File, error) {
+	if r.data.db == nil {
+		return nil, errFileDisabled
+	}
+	var f biz.File
+	err := r.data.db.QueryRowContext(ctx, "SELECT "+fileColumns+" FROM file_object WHERE id = ?", id).
+		Scan(&f.ID, &f.Name, &f.Key, &f.Size, &f.ContentType, &f.SHA256, &f.Owner, &f.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, biz.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *fileRepo) List(ctx context.Context, owner string, page query.PageRequest) (*query.Page[*biz.File], error) {
+	if r.data.db == nil {
+		return nil, errFileDisabled
+	}
+	q, err := query.Parse(page, fileFields, query.WithDefaultOrder("createdAt desc"), query.WithTiebreak("id"))
+	if err != nil {
+		return nil, biz.InvalidQuery(err)
+	}
+	where, args := q.Where()
+	if where == "" {
+		where = " WHERE owner = ?"
+	} else {
+		where = " WHERE owner = ? AND (" + strings.TrimPrefix(where, " WHERE ") + ")"
+	}
+	args = append([]any{owner}, args...)
+
+	var total int64
+	if err := r.data.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM file_object"+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+	rows, err := r.data.db.QueryContext(ctx,
+		"SELECT "+fileColumns+" FROM file_object"+where+q.OrderBy()+" LIMIT ? OFFSET ?",
+		append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rv := &query.Page[*biz.File]{TotalSize: total, NextPageToken: q.NextPageToken(total)}
+	for rows.Next() {
+		var f biz.File
+		if err := rows.Scan(&f.ID, &f.Name, &f.Key, &f.Size, &f.ContentType, &f.SHA256, &f.Owner, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		rv.Items = append(rv.Items, &f)
+	}
+	return rv, rows.Err()
+}
+
+func (r *fileRepo) Delete(ctx context.Context, id int64) error {
+	if r.data.db == nil {
+		return errFileDisabled
+	}
+	res, err := r.data.db.ExecContext(ctx, "DELETE FROM file_object WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return biz.ErrFileNotFound
+	}
+	return nil
+}
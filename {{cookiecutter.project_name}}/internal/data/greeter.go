@@ -2,41 +2,184 @@ package data
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
 
 	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/cache"
+	"{{cookiecutter.project_name}}/pkg/database"
+	"{{cookiecutter.project_name}}/pkg/query"
 
 	"github.com/go-kratos/kratos/v2/log"
 )
 
+const greeterColumns = "id, hello, age, sort_order, hobby, birthday"
+
+// greeterFields 列表接口允许过滤与排序的字段，key 为 API 字段名
+var greeterFields = query.Fields{
+	"id":       "id",
+	"username": "hello",
+	"age":      "age",
+	"order":    "sort_order",
+	"hobby":    "hobby",
+	"birthday": "birthday",
+}
+
+// parseGreeterQuery 解析列表参数，默认按 ID 升序，并以 ID 保证分页稳定
+func parseGreeterQuery(page query.PageRequest) (*query.Query, error) {
+	q, err := query.Parse(page, greeterFields, query.WithDefaultOrder("id"), query.WithTiebreak("id"))
+	if err != nil {
+		return nil, biz.InvalidQuery(err)
+	}
+	return q, nil
+}
+
 type greeterRepo struct {
-	data *Data
-	log  *log.Helper
+	data  *Data
+	log   *log.Helper
+	cache *cache.Cache[*biz.Greeter]
 }
 
 // NewGreeterRepo .
 func NewGreeterRepo(data *Data, logger log.Logger) biz.GreeterRepo {
+	if data.db == nil {
+		return newGreeterMemoryRepo(logger)
+	}
 	return &greeterRepo{
 		data: data,
 		log:  log.NewHelper(logger),
+		cache: cache.New[*biz.Greeter](data.rdb, "greeter",
+			cache.WithNegative(biz.ErrUserNotFound, biz.IsUserNotFound, 0)),
 	}
 }
 
 func (r *greeterRepo) Save(ctx context.Context, g *biz.Greeter) (*biz.Greeter, error) {
-	return g, nil
+	hobby, err := json.Marshal(g.Hobby)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.data.db.ExecContext(ctx,
+		"INSERT INTO greeter (id, hello, age, sort_order, hobby, birthday) VALUES (?, ?, ?, ?, ?, ?)",
+		g.ID, g.Hello, nullAge(g.Age), g.Order, string(hobby), nullTime(g.Birthday))
+	if err != nil {
+		return nil, err
+	}
+	saved := *g
+	return &saved, nil
 }
 
 func (r *greeterRepo) Update(ctx context.Context, g *biz.Greeter) (*biz.Greeter, error) {
+	hobby, err := json.Marshal(g.Hobby)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.data.db.ExecContext(ctx,
+		"UPDATE greeter SET hello = ?, age = ?, sort_order = ?, hobby = ?, birthday = ? WHERE id = ?",
+		g.Hello, nullAge(g.Age), g.Order, string(hobby), nullTime(g.Birthday), g.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 事务中的更新在提交后再失效缓存，避免并发读取回填旧数据
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := r.cache.Delete(ctx, strconv.FormatInt(g.ID, 10)); err != nil {
+			r.log.WithContext(ctx).Warnf("failed to invalidate greeter cache: %v", err)
+		}
+	})
+	// MySQL 在数据未变化时影响行数为 0，需再确认记录是否存在
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		if _, err := r.FindByID(ctx, g.ID); err != nil {
+			return nil, err
+		}
+	}
 	return g, nil
 }
 
-func (r *greeterRepo) FindByID(context.Context, int64) (*biz.Greeter, error) {
-	return nil, nil
+func (r *greeterRepo) FindByID(ctx context.Context, id int64) (*biz.Greeter, error) {
+	// 事务中可能读到未提交的数据，不经过缓存
+	if database.InTransaction(ctx) {
+		return r.findByID(ctx, id)
+	}
+	return r.cache.Get(ctx, strconv.FormatInt(id, 10), func(ctx context.Context) (*biz.Greeter, error) {
+		return r.findByID(ctx, id)
+	})
+}
+
+func (r *greeterRepo) findByID(ctx context.Context, id int64) (*biz.Greeter, error) {
+	row := r.data.db.QueryRowContext(ctx, "SELECT "+greeterColumns+" FROM greeter WHERE id = ?", id)
+	g, err := scanGreeter(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, biz.ErrUserNotFound
+	}
+	return g, err
+}
+
+func (r *greeterRepo) List(ctx context.Context, page query.PageRequest) (*query.Page[*biz.Greeter], error) {
+	q, err := parseGreeterQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	where, args := q.Where()
+	var total int64
+	if err := r.data.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM greeter"+where, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+	rows, err := r.data.db.QueryContext(ctx,
+		"SELECT "+greeterColumns+" FROM greeter"+where+q.OrderBy()+" LIMIT ? OFFSET ?",
+		append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rv := &query.Page[*biz.Greeter]{TotalSize: total, NextPageToken: q.NextPageToken(total)}
+	for rows.Next() {
+		g, err := scanGreeter(rows)
+		if err != nil {
+			return nil, err
+		}
+		rv.Items = append(rv.Items, g)
+	}
+	return rv, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanGreeter(s scanner) (*biz.Greeter, error) {
+	var (
+		g        biz.Greeter
+		age      sql.NullInt64
+		hobby    sql.NullString
+		birthday sql.NullTime
+	)
+	if err := s.Scan(&g.ID, &g.Hello, &age, &g.Order, &hobby, &birthday); err != nil {
+		return nil, err
+	}
+	if age.Valid {
+		v := uint32(age.Int64)
+		g.Age = &v
+	}
+	if hobby.Valid && hobby.String != "" {
+		if err := json.Unmarshal([]byte(hobby.String), &g.Hobby); err != nil {
+			return nil, err
+		}
+	}
+	if birthday.Valid {
+		g.Birthday = birthday.Time
+	}
+	return &g, nil
 }
 
-func (r *greeterRepo) ListByHello(context.Context, string) ([]*biz.Greeter, error) {
-	return nil, nil
+func nullAge(age *uint32) sql.NullInt64 {
+	if age == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*age), Valid: true}
 }
 
-func (r *greeterRepo) ListAll(context.Context) ([]*biz.Greeter, error) {
-	return nil, nil
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
 }
@@ -0,0 +1,104 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/query"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// greeterMemoryRepo 未配置数据库时使用的内存存储，便于本地开发
+type greeterMemoryRepo struct {
+	log *log.Helper
+
+	mu       sync.RWMutex
+	greeters map[int64]*biz.Greeter
+}
+
+func newGreeterMemoryRepo(logger log.Logger) biz.GreeterRepo {
+	return &greeterMemoryRepo{
+		log:      log.NewHelper(logger),
+		greeters: make(map[int64]*biz.Greeter),
+	}
+}
+
+func (r *greeterMemoryRepo) Save(ctx context.Context, g *biz.Greeter) (*biz.Greeter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := cloneGreeter(g)
+	r.greeters[saved.ID] = saved
+	return cloneGreeter(saved), nil
+}
+
+func (r *greeterMemoryRepo) Update(ctx context.Context, g *biz.Greeter) (*biz.Greeter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.greeters[g.ID]; !ok {
+		return nil, biz.ErrUserNotFound
+	}
+	r.greeters[g.ID] = cloneGreeter(g)
+	return cloneGreeter(g), nil
+}
+
+func (r *greeterMemoryRepo) FindByID(ctx context.Context, id int64) (*biz.Greeter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.greeters[id]
+	if !ok {
+		return nil, biz.ErrUserNotFound
+	}
+	return cloneGreeter(g), nil
+}
+
+func (r *greeterMemoryRepo) List(ctx context.Context, page query.PageRequest) (*query.Page[*biz.Greeter], error) {
+	q, err := parseGreeterQuery(page)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	all := make([]*biz.Greeter, 0, len(r.greeters))
+	for _, g := range r.greeters {
+		all = append(all, cloneGreeter(g))
+	}
+	r.mu.RUnlock()
+	// 先按 ID 排序，保证排序字段相同时结果稳定
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return query.Apply(q, all, greeterField), nil
+}
+
+// greeterField 返回 API 字段对应的值，供内存过滤与排序使用
+func greeterField(g *biz.Greeter, field string) any {
+	switch field {
+	case "id":
+		return g.ID
+	case "username":
+		return g.Hello
+	case "age":
+		return g.Age
+	case "order":
+		return g.Order
+	case "hobby":
+		return g.Hobby
+	case "birthday":
+		if g.Birthday.IsZero() {
+			return nil
+		}
+		return g.Birthday
+	}
+	return nil
+}
+
+// cloneGreeter 复制 Greeter，避免调用方修改存储中的数据
+func cloneGreeter(g *biz.Greeter) *biz.Greeter {
+	c := *g
+	if g.Age != nil {
+		age := *g.Age
+		c.Age = &age
+	}
+	c.Hobby = append([]string(nil), g.Hobby...)
+	return &c
+}
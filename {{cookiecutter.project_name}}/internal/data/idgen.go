@@ -0,0 +1,71 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/idgen"
+	"{{cookiecutter.project_name}}/pkg/nacos"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const leaseTimeout = 10 * time.Second
+
+// NewIDGenerator 根据 conf.Global.Idgen 创建 ID 生成器
+// redis / nacos 模式下从共享存储租用 worker id，cleanup 时释放
+func NewIDGenerator(c *conf.Config, data *Data, nac *nacos.Client, logger log.Logger) (biz.IDGenerator, func(), error) {
+	g := c.GetGlobal()
+	ic := g.GetIdgen()
+	preferred := idgen.WorkerFromConfig(g.GetId())
+	if ic != nil && ic.WorkerId != nil {
+		preferred = int64(ic.GetWorkerId())
+	}
+
+	var store idgen.Store
+	switch ic.GetWorkerSource() {
+	case "", "config":
+		gen, err := idgen.New(preferred)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.NewHelper(logger).Infof("idgen: using worker id %d from config", preferred)
+		return gen, func() {}, nil
+	case "redis":
+		if data.rdb == nil {
+			return nil, nil, fmt.Errorf("idgen: worker source redis requires data.redis")
+		}
+		store = idgen.NewRedisStore(data.rdb, fmt.Sprintf("idgen:%s:worker", g.GetAppName()))
+	case "nacos":
+		if nac == nil {
+			return nil, nil, fmt.Errorf("idgen: worker source nacos requires nacos.enable")
+		}
+		store = idgen.NewNacosStore(nac.NamingClient, g.GetAppName()+"-idgen", c.GetNacos().GetDiscovery().GetGroupName(), g.GetId())
+	default:
+		return nil, nil, fmt.Errorf("idgen: unknown worker source %q", ic.GetWorkerSource())
+	}
+
+	var ttl time.Duration
+	if s := ic.GetLeaseTtl(); s != "" {
+		var err error
+		if ttl, err = time.ParseDuration(s); err != nil {
+			return nil, nil, fmt.Errorf("invalid idgen leaseTtl: %w", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), leaseTimeout)
+	defer cancel()
+	lease, err := idgen.NewLease(ctx, store, idgen.WithTTL(ttl), idgen.WithPreferred(preferred), idgen.WithLogger(logger))
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), leaseTimeout)
+		defer cancel()
+		if err := lease.Close(ctx); err != nil {
+			log.NewHelper(logger).Errorf("idgen: failed to release worker lease: %v", err)
+		}
+	}
+	return idgen.NewWithLease(lease), cleanup, nil
+}
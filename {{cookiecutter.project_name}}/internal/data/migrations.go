@@ -0,0 +1,15 @@
+package data
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations
+var migrationFS embed.FS
+
+// Migrations 返回嵌入的迁移文件，按方言划分子目录
+func Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFS, "migrations")
+	return sub
+}
@@ -0,0 +1,9 @@
+CREATE TABLE greeter (
+    id         BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    hello      VARCHAR(100) NOT NULL,
+    age        INT UNSIGNED NULL,
+    sort_order INT UNSIGNED NOT NULL DEFAULT 0,
+    hobby      TEXT         NULL,
+    birthday   DATETIME     NULL,
+    KEY idx_greeter_hello (hello)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,20 @@
+CREATE TABLE user_account (
+    id            BIGINT       NOT NULL PRIMARY KEY,
+    username      VARCHAR(64)  NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    nickname      VARCHAR(64)  NOT NULL DEFAULT '',
+    email         VARCHAR(128) NOT NULL DEFAULT '',
+    roles         VARCHAR(255) NOT NULL DEFAULT '',
+    disabled      TINYINT(1)   NOT NULL DEFAULT 0,
+    created_at    DATETIME     NOT NULL,
+    updated_at    DATETIME     NOT NULL,
+    UNIQUE KEY uk_user_account_username (username)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE user_session (
+    id         CHAR(64)  NOT NULL PRIMARY KEY,
+    user_id    BIGINT    NOT NULL,
+    expires_at DATETIME  NOT NULL,
+    created_at DATETIME  NOT NULL,
+    KEY idx_user_session_user_id (user_id)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+DROP TABLE dict_item;
+DROP TABLE dict;
@@ -0,0 +1,18 @@
+CREATE TABLE dict (
+    code        VARCHAR(64)  NOT NULL PRIMARY KEY,
+    name        VARCHAR(128) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    version     BIGINT       NOT NULL DEFAULT 1,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE dict_item (
+    dict_code  VARCHAR(64)   NOT NULL,
+    value      VARCHAR(128)  NOT NULL,
+    label      VARCHAR(128)  NOT NULL,
+    sort_order INT           NOT NULL DEFAULT 0,
+    disabled   TINYINT(1)    NOT NULL DEFAULT 0,
+    extra      VARCHAR(1024) NOT NULL DEFAULT '',
+    PRIMARY KEY (dict_code, value)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
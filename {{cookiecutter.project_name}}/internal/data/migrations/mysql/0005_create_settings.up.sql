@@ -0,0 +1,9 @@
+CREATE TABLE app_settings (
+    scope      VARCHAR(16) NOT NULL,
+    owner      VARCHAR(64) NOT NULL,
+    name       VARCHAR(64) NOT NULL,
+    value      TEXT        NOT NULL,
+    version    BIGINT      NOT NULL DEFAULT 1,
+    updated_at DATETIME    NOT NULL,
+    PRIMARY KEY (scope, owner, name)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
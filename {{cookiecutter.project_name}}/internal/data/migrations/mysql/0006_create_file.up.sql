@@ -0,0 +1,11 @@
+CREATE TABLE file_object (
+    id           BIGINT       NOT NULL PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    object_key   VARCHAR(255) NOT NULL,
+    size         BIGINT       NOT NULL,
+    content_type VARCHAR(128) NOT NULL DEFAULT '',
+    sha256       CHAR(64)     NOT NULL,
+    owner        VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at   DATETIME     NOT NULL,
+    KEY idx_file_object_owner (owner, created_at)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1 @@
+ALTER TABLE user_account ADD COLUMN password_changed_at DATETIME NULL;
@@ -0,0 +1,20 @@
+CREATE TABLE user_account (
+    id            BIGINT       PRIMARY KEY,
+    username      VARCHAR(64)  NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    nickname      VARCHAR(64)  NOT NULL DEFAULT '',
+    email         VARCHAR(128) NOT NULL DEFAULT '',
+    roles         VARCHAR(255) NOT NULL DEFAULT '',
+    disabled      BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMP    NOT NULL,
+    updated_at    TIMESTAMP    NOT NULL
+);
+CREATE UNIQUE INDEX uk_user_account_username ON user_account (username);
+
+CREATE TABLE user_session (
+    id         CHAR(64)  PRIMARY KEY,
+    user_id    BIGINT    NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_user_session_user_id ON user_session (user_id);
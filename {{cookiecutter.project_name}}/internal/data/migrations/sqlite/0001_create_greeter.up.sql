@@ -0,0 +1,9 @@
+CREATE TABLE greeter (
+    id         INTEGER      PRIMARY KEY AUTOINCREMENT,
+    hello      VARCHAR(100) NOT NULL,
+    age        INTEGER      NULL,
+    sort_order INTEGER      NOT NULL DEFAULT 0,
+    hobby      TEXT         NULL,
+    birthday   TIMESTAMP    NULL
+);
+CREATE INDEX idx_greeter_hello ON greeter (hello);
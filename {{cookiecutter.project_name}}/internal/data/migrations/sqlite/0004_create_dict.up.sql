@@ -0,0 +1,18 @@
+CREATE TABLE dict (
+    code        VARCHAR(64)  PRIMARY KEY,
+    name        VARCHAR(128) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    version     INTEGER      NOT NULL DEFAULT 1,
+    created_at  TIMESTAMP    NOT NULL,
+    updated_at  TIMESTAMP    NOT NULL
+);
+
+CREATE TABLE dict_item (
+    dict_code  VARCHAR(64)   NOT NULL,
+    value      VARCHAR(128)  NOT NULL,
+    label      VARCHAR(128)  NOT NULL,
+    sort_order INTEGER       NOT NULL DEFAULT 0,
+    disabled   BOOLEAN       NOT NULL DEFAULT FALSE,
+    extra      VARCHAR(1024) NOT NULL DEFAULT '',
+    PRIMARY KEY (dict_code, value)
+);
@@ -0,0 +1 @@
+DROP TABLE file_object;
@@ -0,0 +1,11 @@
+CREATE TABLE file_object (
+    id           INTEGER      PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    object_key   VARCHAR(255) NOT NULL,
+    size         INTEGER      NOT NULL,
+    content_type VARCHAR(128) NOT NULL DEFAULT '',
+    sha256       CHAR(64)     NOT NULL,
+    owner        VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at   TIMESTAMP    NOT NULL
+);
+CREATE INDEX idx_file_object_owner ON file_object (owner, created_at);
@@ -0,0 +1 @@
+ALTER TABLE user_account DROP COLUMN password_changed_at;
@@ -0,0 +1 @@
+ALTER TABLE user_account ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,32 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/notify"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const notifyTimeout = 5 * time.Second
+
+// NewNotifier 创建变更通知，配置 Redis 时通过发布订阅通知所有实例，否则仅通知本实例
+func NewNotifier(c *conf.Config, data *Data, logger log.Logger) (biz.Notifier, func(), error) {
+	if data.rdb == nil {
+		return notify.NewLocal(), func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	n, err := notify.NewRedis(ctx, data.rdb, fmt.Sprintf("notify:%s", c.GetGlobal().GetAppName()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return n, func() {
+		if err := n.Close(); err != nil {
+			log.NewHelper(logger).Errorf("notify: failed to close: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,29 @@
+package data
+
+import (
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/ratelimit"
+)
+
+// NewRateLimiter 根据 conf.Server.RateLimit 创建限流器，未启用时返回 nil
+// redis 模式下计数保存在 data.redis 中，所有实例共享
+func NewRateLimiter(c *conf.Config, data *Data) (*ratelimit.RateLimiter, error) {
+	rc := c.GetServer().GetRateLimit()
+	if !rc.GetEnable() {
+		return nil, nil
+	}
+	var limiter ratelimit.Limiter
+	switch rc.GetMode() {
+	case "", ratelimit.ModeLocal:
+		limiter = ratelimit.NewLocalLimiter()
+	case ratelimit.ModeRedis:
+		if data.rdb == nil {
+			return nil, fmt.Errorf("ratelimit: mode redis requires data.redis")
+		}
+		limiter = ratelimit.NewRedisLimiter(data.rdb, fmt.Sprintf("ratelimit:%s:", c.GetGlobal().GetAppName()))
+	default:
+		return nil, fmt.Errorf("ratelimit: unknown mode %q", rc.GetMode())
+	}
+	return ratelimit.New(rc, limiter)
+}
@@ -0,0 +1,175 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/cache"
+	"{{cookiecutter.project_name}}/pkg/database"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type settingsRepo struct {
+	data  *Data
+	log   *log.Helper
+	cache *cache.Cache[*biz.Settings]
+}
+
+// NewSettingsRepo 未配置数据库时使用内存存储，配置 Redis 时缓存配置，不存在的配置缓存为 null
+func NewSettingsRepo(data *Data, logger log.Logger) biz.SettingsRepo {
+	if data.db == nil {
+		return newSettingsMemoryRepo()
+	}
+	return &settingsRepo{data: data, log: log.NewHelper(logger), cache: cache.New[*biz.Settings](data.rdb, "settings")}
+}
+
+func (r *settingsRepo) Find(ctx context.Context, scope, owner, name string) (*biz.Settings, error) {
+	if database.InTransaction(ctx) {
+		return r.find(ctx, scope, owner, name)
+	}
+	return r.cache.Get(ctx, settingsKey(scope, owner, name), func(ctx context.Context) (*biz.Settings, error) {
+		return r.find(ctx, scope, owner, name)
+	})
+}
+
+func (r *settingsRepo) find(ctx context.Context, scope, owner, name string) (*biz.Settings, error) {
+	s := biz.Settings{Scope: scope, Owner: owner, Name: name}
+	var value string
+	err := r.data.db.QueryRowContext(ctx,
+		"SELECT value, version, updated_at FROM app_settings WHERE scope = ? AND owner = ? AND name = ?", scope, owner, name).
+		Scan(&value, &s.Version, &s.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(value), &s.Value); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *settingsRepo) Save(ctx context.Context, s *biz.Settings) (saved *biz.Settings, err error) {
+	value, err := json.Marshal(s.Value)
+	if err != nil {
+		return nil, err
+	}
+	err = r.data.db.InTx(ctx, func(ctx context.Context) error {
+		q := "UPDATE app_settings SET value = ?, version = version + 1, updated_at = ? WHERE scope = ? AND owner = ? AND name = ?"
+		args := []any{string(value), s.UpdatedAt, s.Scope, s.Owner, s.Name}
+		if s.Version != 0 {
+			q += " AND version = ?"
+			args = append(args, s.Version)
+		}
+		res, err := r.data.db.ExecContext(ctx, q, args...)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			if s.Version != 0 {
+				return biz.ErrSettingsVersionConflict
+			}
+			if _, err := r.data.db.ExecContext(ctx,
+				"INSERT INTO app_settings (scope, owner, name, value, version, updated_at) VALUES (?, ?, ?, ?, 1, ?)",
+				s.Scope, s.Owner, s.Name, string(value), s.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		if saved, err = r.find(ctx, s.Scope, s.Owner, s.Name); err != nil {
+			return err
+		}
+		r.invalidate(ctx, s.Scope, s.Owner, s.Name)
+		return nil
+	})
+	return saved, err
+}
+
+func (r *settingsRepo) Delete(ctx context.Context, scope, owner, name string) error {
+	if _, err := r.data.db.ExecContext(ctx,
+		"DELETE FROM app_settings WHERE scope = ? AND owner = ? AND name = ?", scope, owner, name); err != nil {
+		return err
+	}
+	r.invalidate(ctx, scope, owner, name)
+	return nil
+}
+
+// invalidate 在事务提交后删除缓存
+func (r *settingsRepo) invalidate(ctx context.Context, scope, owner, name string) {
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := r.cache.Delete(ctx, settingsKey(scope, owner, name)); err != nil {
+			r.log.WithContext(ctx).Warnf("failed to invalidate settings cache: %v", err)
+		}
+	})
+}
+
+func settingsKey(scope, owner, name string) string {
+	return scope + ":" + owner + ":" + name
+}
+
+// settingsMemoryRepo 未配置数据库时使用的内存存储
+type settingsMemoryRepo struct {
+	mu       sync.RWMutex
+	settings map[string]*biz.Settings
+}
+
+func newSettingsMemoryRepo() biz.SettingsRepo {
+	return &settingsMemoryRepo{settings: make(map[string]*biz.Settings)}
+}
+
+func (r *settingsMemoryRepo) Find(ctx context.Context, scope, owner, name string) (*biz.Settings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.settings[settingsKey(scope, owner, name)]
+	if !ok {
+		return nil, nil
+	}
+	return cloneSettings(s)
+}
+
+func (r *settingsMemoryRepo) Save(ctx context.Context, s *biz.Settings) (*biz.Settings, error) {
+	saved, err := cloneSettings(s)
+	if err != nil {
+		return nil, err
+	}
+	key := settingsKey(s.Scope, s.Owner, s.Name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.settings[key]
+	switch {
+	case s.Version != 0 && (!ok || old.Version != s.Version):
+		return nil, biz.ErrSettingsVersionConflict
+	case ok:
+		saved.Version = old.Version + 1
+	default:
+		saved.Version = 1
+	}
+	r.settings[key] = saved
+	return cloneSettings(saved)
+}
+
+func (r *settingsMemoryRepo) Delete(ctx context.Context, scope, owner, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.settings, settingsKey(scope, owner, name))
+	return nil
+}
+
+// cloneSettings 通过 JSON 深拷贝配置内容，与 SQL 存储的取值类型保持一致
+func cloneSettings(s *biz.Settings) (*biz.Settings, error) {
+	c := *s
+	data, err := json.Marshal(s.Value)
+	if err != nil {
+		return nil, err
+	}
+	c.Value = nil
+	if err := json.Unmarshal(data, &c.Value); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
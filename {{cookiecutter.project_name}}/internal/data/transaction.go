@@ -0,0 +1,24 @@
+package data
+
+import (
+	"context"
+
+	"{{cookiecutter.project_name}}/internal/biz"
+)
+
+type transaction struct {
+	data *Data
+}
+
+// NewTransaction .
+func NewTransaction(data *Data) biz.Transaction {
+	return &transaction{data: data}
+}
+
+// InTx 未配置数据库时直接执行 fn，内存仓储不支持回滚
+func (t *transaction) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t.data.db == nil {
+		return fn(ctx)
+	}
+	return t.data.db.InTx(ctx, fn)
+}
@@ -0,0 +1,241 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/user"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/cache"
+	"{{cookiecutter.project_name}}/pkg/database"
+	"{{cookiecutter.project_name}}/pkg/password"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	accountColumns       = "id, username, password_hash, nickname, email, roles, disabled, password_changed_at, token_version, created_at, updated_at"
+	defaultRefreshExpire = 7 * 24 * time.Hour
+)
+
+// errUserDisabled 未启用 conf.User 时调用用户接口
+var errUserDisabled = errors.New("user module is not enabled")
+
+type userRepo struct {
+	data *Data
+	log  *log.Helper
+	// versions 缓存用户的凭证版本，每个认证请求都会读取
+	versions *cache.Cache[int64]
+}
+
+// NewUserRepo 创建用户仓储，启用 conf.User 时要求同时启用 auth 并配置数据库
+func NewUserRepo(c *conf.Config, data *Data, logger log.Logger) (biz.UserRepo, error) {
+	if c.GetUser().GetEnable() {
+		if !c.GetAuth().GetEnable() {
+			return nil, errors.New("user: requires auth.enable")
+		}
+		if data.db == nil {
+			return nil, errors.New("user: requires data.database")
+		}
+	}
+	return &userRepo{
+		data: data,
+		log:  log.NewHelper(logger),
+		versions: cache.New[int64](data.rdb, "user:token_version",
+			cache.WithNegative(biz.ErrAccountNotFound, user.IsAccountNotFound, 0)),
+	}, nil
+}
+
+// NewPasswordHasher 根据 conf.User.PasswordHash 创建密码哈希
+func NewPasswordHasher(c *conf.Config) (biz.PasswordHasher, error) {
+	return password.New(c.GetUser().GetPasswordHash())
+}
+
+// NewUserConfig 解析 conf.User
+func NewUserConfig(c *conf.Config) (*biz.UserConfig, error) {
+	uc := c.GetUser()
+	bc := &biz.UserConfig{Enable: uc.GetEnable(), RefreshExpire: defaultRefreshExpire, LoginCaptcha: uc.GetLoginCaptcha(), DefaultRoles: uc.GetDefaultRoles()}
+	if s := uc.GetRefreshExpire(); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid user refreshExpire %q", s)
+		}
+		bc.RefreshExpire = d
+	}
+	return bc, nil
+}
+
+type tokenIssuer struct {
+	j *auth.JWT
+}
+
+// NewTokenIssuer 使用 JWT 签发 access token，Subject 为用户 ID
+func NewTokenIssuer(j *auth.JWT) biz.TokenIssuer {
+	return &tokenIssuer{j: j}
+}
+
+func (t *tokenIssuer) Issue(a *biz.Account) (string, time.Duration, error) {
+	if t.j == nil {
+		return "", 0, errors.New("auth is not enabled")
+	}
+	claims := &auth.Claims{Roles: a.Roles, TokenVersion: a.TokenVersion}
+	claims.Subject = strconv.FormatInt(a.ID, 10)
+	token, err := t.j.Sign(claims)
+	return token, t.j.Expire(), err
+}
+
+func (r *userRepo) Create(ctx context.Context, a *biz.Account) error {
+	if r.data.db == nil {
+		return errUserDisabled
+	}
+	_, err := r.data.db.ExecContext(ctx,
+		"INSERT INTO user_account ("+accountColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		a.ID, a.Username, a.PasswordHash, a.Nickname, a.Email, strings.Join(a.Roles, ","), a.Disabled,
+		nullTime(a.PasswordChangedAt), a.TokenVersion, a.CreatedAt, a.UpdatedAt)
+	return err
+}
+
+func (r *userRepo) FindByID(ctx context.Context, id int64) (*biz.Account, error) {
+	return r.find(ctx, "id = ?", id)
+}
+
+func (r *userRepo) FindByUsername(ctx context.Context, username string) (*biz.Account, error) {
+	return r.find(ctx, "username = ?", username)
+}
+
+func (r *userRepo) find(ctx context.Context, cond string, arg any) (*biz.Account, error) {
+	if r.data.db == nil {
+		return nil, errUserDisabled
+	}
+	var (
+		a         biz.Account
+		roles     string
+		changedAt sql.NullTime
+	)
+	err := r.data.db.QueryRowContext(ctx, "SELECT "+accountColumns+" FROM user_account WHERE "+cond, arg).
+		Scan(&a.ID, &a.Username, &a.PasswordHash, &a.Nickname, &a.Email, &roles, &a.Disabled, &changedAt, &a.TokenVersion, &a.CreatedAt, &a.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, biz.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if roles != "" {
+		a.Roles = strings.Split(roles, ",")
+	}
+	a.PasswordChangedAt = changedAt.Time
+	return &a, nil
+}
+
+func (r *userRepo) UpdateProfile(ctx context.Context, a *biz.Account) error {
+	if r.data.db == nil {
+		return errUserDisabled
+	}
+	_, err := r.data.db.ExecContext(ctx,
+		"UPDATE user_account SET nickname = ?, email = ?, updated_at = ? WHERE id = ?",
+		a.Nickname, a.Email, a.UpdatedAt, a.ID)
+	return err
+}
+
+func (r *userRepo) UpdatePassword(ctx context.Context, id int64, hash string) error {
+	if r.data.db == nil {
+		return errUserDisabled
+	}
+	_, err := r.data.db.ExecContext(ctx,
+		"UPDATE user_account SET password_hash = ?, updated_at = ? WHERE id = ?", hash, time.Now(), id)
+	return err
+}
+
+func (r *userRepo) ChangePassword(ctx context.Context, id int64, hash string, changedAt time.Time) error {
+	if r.data.db == nil {
+		return errUserDisabled
+	}
+	if _, err := r.data.db.ExecContext(ctx,
+		"UPDATE user_account SET password_hash = ?, password_changed_at = ?, token_version = token_version + 1, updated_at = ? WHERE id = ?",
+		hash, changedAt, time.Now(), id); err != nil {
+		return err
+	}
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := r.versions.Delete(ctx, strconv.FormatInt(id, 10)); err != nil {
+			r.log.WithContext(ctx).Warnf("failed to invalidate token version cache: %v", err)
+		}
+	})
+	return nil
+}
+
+func (r *userRepo) TokenVersion(ctx context.Context, id int64) (int64, error) {
+	if r.data.db == nil {
+		return 0, errUserDisabled
+	}
+	return r.versions.Get(ctx, strconv.FormatInt(id, 10), func(ctx context.Context) (int64, error) {
+		var version int64
+		err := r.data.db.QueryRowContext(ctx, "SELECT token_version FROM user_account WHERE id = ?", id).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, biz.ErrAccountNotFound
+		}
+		return version, err
+	})
+}
+
+func (r *userRepo) CreateSession(ctx context.Context, s *biz.Session) error {
+	if r.data.db == nil {
+		return errUserDisabled
+	}
+	// 顺带清理该用户已过期的会话
+	if _, err := r.data.db.ExecContext(ctx,
+		"DELETE FROM user_session WHERE user_id = ? AND expires_at < ?", s.UserID, s.CreatedAt); err != nil {
+		return err
+	}
+	_, err := r.data.db.ExecContext(ctx,
+		"INSERT INTO user_session (id, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)",
+		s.ID, s.UserID, s.ExpiresAt, s.CreatedAt)
+	return err
+}
+
+func (r *userRepo) TakeSession(ctx context.Context, id string) (*biz.Session, error) {
+	if r.data.db == nil {
+		return nil, errUserDisabled
+	}
+	s := biz.Session{ID: id}
+	err := r.data.db.QueryRowContext(ctx,
+		"SELECT user_id, expires_at, created_at FROM user_session WHERE id = ?", id).
+		Scan(&s.UserID, &s.ExpiresAt, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, biz.ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.data.db.ExecContext(ctx, "DELETE FROM user_session WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	// 并发使用同一 refresh token 时只有删除成功的请求有效
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, biz.ErrRefreshTokenInvalid
+	}
+	return &s, nil
+}
+
+func (r *userRepo) DeleteSession(ctx context.Context, id string, userID int64) error {
+	if r.data.db == nil {
+		return errUserDisabled
+	}
+	_, err := r.data.db.ExecContext(ctx, "DELETE FROM user_session WHERE id = ? AND user_id = ?", id, userID)
+	return err
+}
+
+func (r *userRepo) DeleteSessions(ctx context.Context, userID int64) error {
+	if r.data.db == nil {
+		return errUserDisabled
+	}
+	_, err := r.data.db.ExecContext(ctx, "DELETE FROM user_session WHERE user_id = ?", userID)
+	return err
+}
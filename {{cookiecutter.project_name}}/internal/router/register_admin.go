@@ -0,0 +1,16 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/api/v1/admin"
+	"{{cookiecutter.project_name}}/internal/service"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterAdminRouter(srv *http.Server, policy *service.PolicyService) *http.Server {
+
+	// 注册管理路由
+	admin.RegisterPolicyHTTPServer(srv, policy)
+	return srv
+
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/api/v1/captcha"
+	"{{cookiecutter.project_name}}/internal/service"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterCaptchaRouter(srv *http.Server, cs *service.CaptchaService) *http.Server {
+
+	// 注册验证码路由
+	captcha.RegisterCaptchaHTTPServer(srv, cs)
+	return srv
+
+}
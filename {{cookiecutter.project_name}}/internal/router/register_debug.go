@@ -0,0 +1,20 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/debug"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterDebugRouter(c *conf.Config, srv *http.Server, hs *http.Server) *http.Server {
+
+	// 注册管理端口的调试路由，压测请求发往业务 HTTP 服务 hs
+	opts := []debug.Option{debug.WithEndpoint(hs.Endpoint)}
+	if dir := c.GetServer().GetAdmin().GetProfileDir(); dir != "" {
+		opts = append(opts, debug.WithProfileDir(dir))
+	}
+	debug.Register(srv, opts...)
+	return srv
+
+}
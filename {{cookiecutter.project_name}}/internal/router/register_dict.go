@@ -0,0 +1,16 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/api/v1/dict"
+	"{{cookiecutter.project_name}}/internal/service"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterDictRouter(srv *http.Server, ds *service.DictService) *http.Server {
+
+	// 注册字典路由
+	dict.RegisterDictHTTPServer(srv, ds)
+	return srv
+
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/api/v1/file"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/service"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterFileRouter(c *conf.Config, srv *http.Server, fs *service.FileService) *http.Server {
+
+	// 注册文件路由，未启用 file 模块时不注册
+	if c.GetFile().GetEnable() {
+		r := srv.Route("/")
+		// 本地存储的下载地址，需在 /api/v1/files/{id} 之前注册
+		r.GET("/api/v1/files/download/{key:.+}", fs.DownloadFile)
+		r.POST("/api/v1/files", fs.UploadFile)
+		file.RegisterFileHTTPServer(srv, fs)
+	}
+	return srv
+
+}
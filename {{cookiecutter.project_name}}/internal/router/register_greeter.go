@@ -10,6 +10,9 @@ import (
 func RegisterGreeterRouter(srv *http.Server, greeter *service.GreeterService) *http.Server {
 
 	// 注册业务路由
+	r := srv.Route("/")
+	r.POST("/api/v1/greeter/users/import", greeter.ImportUsers)
+	r.GET("/api/v1/greeter/users/export", greeter.ExportUsers)
 	v1.RegisterGreeterHTTPServer(srv, greeter)
 	return srv
 
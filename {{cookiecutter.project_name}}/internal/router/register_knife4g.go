@@ -17,7 +17,7 @@ func RegisterKnife4g(cfg *conf.Config, srv *http.Server, logger log.Logger) *htt
 
 // registerKnife4gDoc 注册 knife4g 文档服务
 func registerKnife4gDoc(c *conf.Config, srv *http.Server, logger log.Logger) {
-	// 加载 OpenAPI 配置（默认使用 docs/api/openapi.yaml）
+	// 加载 OpenAPI 配置（嵌入的 docs/api/openapi.yaml，未生成时由 proto 描述生成）
 	config, err := knife4g.NewDefaultConfig(c.Global.AppName)
 	if err != nil {
 		log.NewHelper(logger).Warnf("Failed to load OpenAPI config: %v", err)
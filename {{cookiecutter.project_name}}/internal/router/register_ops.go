@@ -0,0 +1,27 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/configs/conf"
+	pkg "{{cookiecutter.project_name}}/pkg/log"
+	"{{cookiecutter.project_name}}/pkg/nacos"
+	"{{cookiecutter.project_name}}/pkg/ops"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterOpsRouter(c *conf.Config, srv *http.Server, hs *http.Server, gs *grpc.Server, checks ops.Checks, nac *nacos.Client) *http.Server {
+
+	// 注册管理端口的运维路由，database.source 为带密码的连接串
+	opts := []ops.Option{
+		ops.WithChecks(checks),
+		ops.WithLogLevel(pkg.Level()),
+		ops.WithConfig(c, "source"),
+	}
+	if c.GetNacos().GetEnable() && nac != nil {
+		opts = append(opts, ops.WithRegistry(nac, c.GetGlobal().GetAppName(), hs.Endpoint, gs.Endpoint))
+	}
+	ops.Register(srv, opts...)
+	return srv
+
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/api/v1/settings"
+	"{{cookiecutter.project_name}}/internal/service"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterSettingsRouter(srv *http.Server, ss *service.SettingsService) *http.Server {
+
+	// 注册配置路由
+	settings.RegisterSettingsHTTPServer(srv, ss)
+	return srv
+
+}
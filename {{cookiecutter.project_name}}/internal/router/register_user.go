@@ -0,0 +1,19 @@
+package router
+
+import (
+	"{{cookiecutter.project_name}}/api/v1/user"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/internal/service"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func RegisterUserRouter(c *conf.Config, srv *http.Server, us *service.UserService) *http.Server {
+
+	// 注册用户路由，未启用 user 模块时不注册
+	if c.GetUser().GetEnable() {
+		user.RegisterUserHTTPServer(srv, us)
+	}
+	return srv
+
+}
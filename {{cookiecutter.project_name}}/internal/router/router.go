@@ -9,6 +9,13 @@ import (
 )
 
 func Route(c *conf.Config, srv *http.Server, logger log.Logger, h *service.Holder) {
-	RegisterKnife4g(c, srv, logger)
 	RegisterGreeterRouter(srv, h.GreeterService)
+	RegisterAdminRouter(srv, h.PolicyService)
+	RegisterCaptchaRouter(srv, h.CaptchaService)
+	RegisterUserRouter(c, srv, h.UserService)
+	RegisterDictRouter(srv, h.DictService)
+	RegisterSettingsRouter(srv, h.SettingsService)
+	RegisterFileRouter(c, srv, h.FileService)
+	// 文档以 "/" 前缀注册，路由按注册顺序匹配，需放在最后
+	RegisterKnife4g(c, srv, logger)
 }
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+
+	"{{cookiecutter.project_name}}/configs/conf"
+	r "{{cookiecutter.project_name}}/internal/router"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/authz"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"{{cookiecutter.project_name}}/pkg/nacos"
+	"{{cookiecutter.project_name}}/pkg/ops"
+	"{{cookiecutter.project_name}}/pkg/tlsconf"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// probePaths 健康检查路径，供探针与负载均衡器调用，不需要认证
+var probePaths = map[string]struct{}{
+	"/healthz": {},
+	"/readyz":  {},
+}
+
+// AdminServer 管理端口的 HTTP 服务
+// 不实现 transport.Endpointer，管理端口不会注册到服务发现中
+type AdminServer struct {
+	srv *http.Server
+}
+
+// Start 启动管理端口
+func (s *AdminServer) Start(ctx context.Context) error {
+	return s.srv.Start(ctx)
+}
+
+// Stop 停止管理端口
+func (s *AdminServer) Stop(ctx context.Context) error {
+	return s.srv.Stop(ctx)
+}
+
+// NewAdminServer 根据 conf.Server.Admin 创建管理端口，未启用时返回 nil
+// 压测接口的目标为业务 HTTP 服务 hs，注册中心状态查询 hs 与 gs 的地址
+func NewAdminServer(c *conf.Config, hs *http.Server, gs *grpc.Server, j *auth.JWT, az *authz.Authorizer, checks ops.Checks, nac *nacos.Client, logger log.Logger) (*AdminServer, error) {
+	ac := c.GetServer().GetAdmin()
+	if !ac.GetEnable() {
+		return nil, nil
+	}
+	if ac.GetToken() == "" && (j == nil || az == nil) {
+		return nil, errors.New("server.admin requires admin.token or enabled auth and authz")
+	}
+	opts := []http.ServerOption{
+		// profile 采集与压测的耗时较长，不使用请求超时
+		http.Timeout(0),
+		http.Middleware(
+			recovery.Recovery(),
+			logging.Server(logger),
+		),
+		http.Filter(adminAuth(ac.GetToken(), j, az)),
+		http.ResponseEncoder(response.ResponseEncoder()),
+		http.ErrorEncoder(response.ErrorEncoder()),
+	}
+	if ac.GetNetwork() != "" {
+		opts = append(opts, http.Network(ac.GetNetwork()))
+	}
+	if ac.GetAddr() != "" {
+		opts = append(opts, http.Address(ac.GetAddr()))
+	}
+	tc, err := tlsconf.Server(ac.GetTls(), logger)
+	if err != nil {
+		return nil, fmt.Errorf("server.admin: %w", err)
+	}
+	if tc != nil {
+		opts = append(opts, http.TLSConfig(tc))
+	}
+	srv := http.NewServer(opts...)
+	r.RegisterOpsRouter(c, srv, hs, gs, checks, nac)
+	r.RegisterDebugRouter(c, srv, hs)
+	return &AdminServer{srv: srv}, nil
+}
+
+// adminAuth 管理端口的认证，filter 对 pprof、metrics 等非 kratos 路由同样生效
+// 配置 token 时校验静态令牌，否则要求拥有管理员角色的 JWT，健康检查路径除外
+func adminAuth(token string, j *auth.JWT, az *authz.Authorizer) http.FilterFunc {
+	encodeError := response.ErrorEncoder()
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, req *nethttp.Request) {
+			if _, ok := probePaths[req.URL.Path]; ok {
+				next.ServeHTTP(w, req)
+				return
+			}
+			scheme, credential, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+			credential = strings.TrimSpace(credential)
+			if !ok || !strings.EqualFold(scheme, "Bearer") || credential == "" {
+				encodeError(w, req, auth.ErrTokenMissing)
+				return
+			}
+			if token != "" {
+				if subtle.ConstantTimeCompare([]byte(credential), []byte(token)) != 1 {
+					encodeError(w, req, auth.ErrTokenInvalid)
+					return
+				}
+				next.ServeHTTP(w, req)
+				return
+			}
+			claims, err := j.Verify(req.Context(), credential)
+			if err != nil {
+				encodeError(w, req, err)
+				return
+			}
+			ctx := auth.NewContext(req.Context(), claims)
+			if !az.IsAdmin(ctx) {
+				encodeError(w, req, authz.ErrPermissionDenied)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
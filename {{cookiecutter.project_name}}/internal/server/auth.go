@@ -0,0 +1,34 @@
+package server
+
+import (
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/authz"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// NewJWT 根据 conf.Auth 创建 JWT，未启用认证时返回 nil
+// check 吊销用户修改密码前签发的 token
+func NewJWT(c *conf.Config, check auth.Check) (*auth.JWT, error) {
+	if !c.GetAuth().GetEnable() {
+		return nil, nil
+	}
+	return auth.NewJWT(c.GetAuth().GetJwt(), auth.WithCheck(check))
+}
+
+// authenticator 认证中间件，未启用认证时直接放行
+func authenticator(c *conf.Config, j *auth.JWT) middleware.Middleware {
+	if j == nil {
+		return func(handler middleware.Handler) middleware.Handler { return handler }
+	}
+	return auth.Server(j, c.GetAuth().GetAllowList()...)
+}
+
+// authorizer 鉴权中间件，未启用鉴权时直接放行
+func authorizer(c *conf.Config, a *authz.Authorizer) middleware.Middleware {
+	if a == nil {
+		return func(handler middleware.Handler) middleware.Handler { return handler }
+	}
+	return authz.Server(a, c.GetAuthz().GetAllowList()...)
+}
@@ -1,9 +1,23 @@
 package server
 
 import (
+	"fmt"
+	"{{cookiecutter.project_name}}/api/v1/admin"
+	"{{cookiecutter.project_name}}/api/v1/captcha"
+	"{{cookiecutter.project_name}}/api/v1/dict"
+	"{{cookiecutter.project_name}}/api/v1/file"
 	v1 "{{cookiecutter.project_name}}/api/v1/helloworld"
+	"{{cookiecutter.project_name}}/api/v1/settings"
+	"{{cookiecutter.project_name}}/api/v1/user"
 	"{{cookiecutter.project_name}}/configs/conf"
 	"{{cookiecutter.project_name}}/internal/service"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/authz"
+	"{{cookiecutter.project_name}}/pkg/i18n"
+	"{{cookiecutter.project_name}}/pkg/middleware"
+	"{{cookiecutter.project_name}}/pkg/overload"
+	"{{cookiecutter.project_name}}/pkg/ratelimit"
+	"{{cookiecutter.project_name}}/pkg/tlsconf"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -13,12 +27,18 @@ import (
 )
 
 // NewGRPCServer new a gRPC server.
-func NewGRPCServer(c *conf.Config, greeter *service.GreeterService, logger log.Logger) *grpc.Server {
+func NewGRPCServer(c *conf.Config, greeter *service.GreeterService, policy *service.PolicyService, cs *service.CaptchaService, us *service.UserService, ds *service.DictService, ss *service.SettingsService, fs *service.FileService, j *auth.JWT, az *authz.Authorizer, rl *ratelimit.RateLimiter, sd *overload.Shedder, logger log.Logger) (*grpc.Server, error) {
 	s := c.GetServer()
 	var opts = []grpc.ServerOption{
 		grpc.Middleware(
 			recovery.Recovery(),
 			logging.Server(logger),
+			i18n.Server(),
+			shedder(sd, logger),
+			authenticator(c, j),
+			rateLimiter(rl, logger),
+			authorizer(c, az),
+			middleware.Validator(),
 		),
 	}
 	if s.Grpc.Network != "" {
@@ -31,7 +51,24 @@ func NewGRPCServer(c *conf.Config, greeter *service.GreeterService, logger log.L
 		duration, _ := time.ParseDuration(s.Http.Timeout)
 		opts = append(opts, grpc.Timeout(duration))
 	}
+	tc, err := tlsconf.Server(s.Grpc.GetTls(), logger)
+	if err != nil {
+		return nil, fmt.Errorf("server.grpc: %w", err)
+	}
+	if tc != nil {
+		opts = append(opts, grpc.TLSConfig(tc))
+	}
 	srv := grpc.NewServer(opts...)
 	v1.RegisterGreeterServer(srv, greeter)
-	return srv
+	admin.RegisterPolicyServer(srv, policy)
+	captcha.RegisterCaptchaServer(srv, cs)
+	if c.GetUser().GetEnable() {
+		user.RegisterUserServer(srv, us)
+	}
+	dict.RegisterDictServer(srv, ds)
+	settings.RegisterSettingsServer(srv, ss)
+	if c.GetFile().GetEnable() {
+		file.RegisterFileServer(srv, fs)
+	}
+	return srv, nil
 }
@@ -1,10 +1,18 @@
 package server
 
 import (
+	"fmt"
 	"{{cookiecutter.project_name}}/configs/conf"
 	r "{{cookiecutter.project_name}}/internal/router"
 	"{{cookiecutter.project_name}}/internal/service"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/authz"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"{{cookiecutter.project_name}}/pkg/i18n"
 	"{{cookiecutter.project_name}}/pkg/middleware"
+	"{{cookiecutter.project_name}}/pkg/overload"
+	"{{cookiecutter.project_name}}/pkg/ratelimit"
+	"{{cookiecutter.project_name}}/pkg/tlsconf"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
@@ -13,22 +21,38 @@ import (
 	"github.com/go-kratos/kratos/v2/transport/http"
 )
 
+// rawResponse 不使用统一响应结构的路由
+var rawResponse = []response.Option{
+	response.WithRawPathPrefix("/doc.html", "/v3/api-docs", "/webjars"),
+}
+
 // NewHTTPServer new an HTTP server.
-func NewHTTPServer(c *conf.Config, sh *service.Holder, log log.Logger) *http.Server {
-	srv := initServer(c, log)
+func NewHTTPServer(c *conf.Config, sh *service.Holder, j *auth.JWT, az *authz.Authorizer, rl *ratelimit.RateLimiter, sd *overload.Shedder, log log.Logger) (*http.Server, error) {
+	srv, err := initServer(c, j, az, rl, sd, log)
+	if err != nil {
+		return nil, err
+	}
 	r.Route(c, srv, log, sh)
-	return srv
+	return srv, nil
 }
 
-func initServer(c *conf.Config, log log.Logger) *http.Server {
+func initServer(c *conf.Config, j *auth.JWT, az *authz.Authorizer, rl *ratelimit.RateLimiter, sd *overload.Shedder, log log.Logger) (*http.Server, error) {
 
 	s := c.GetServer()
 	var opts = []http.ServerOption{
 		http.Middleware(
 			recovery.Recovery(),
 			logging.Server(log),
-			middleware.Cors(c.Server.HttpCors),
+			i18n.Server(),
+			shedder(sd, log),
+			authenticator(c, j),
+			rateLimiter(rl, log),
+			authorizer(c, az),
+			middleware.Validator(),
 		),
+		http.Filter(middleware.Cors(s.HttpCors)),
+		http.ResponseEncoder(response.ResponseEncoder(rawResponse...)),
+		http.ErrorEncoder(response.ErrorEncoder(rawResponse...)),
 	}
 	if s.Http.Network != "" {
 		opts = append(opts, http.Network(s.Http.Network))
@@ -40,7 +64,14 @@ func initServer(c *conf.Config, log log.Logger) *http.Server {
 		duration, _ := time.ParseDuration(s.Http.Timeout)
 		opts = append(opts, http.Timeout(duration))
 	}
+	tc, err := tlsconf.Server(s.Http.GetTls(), log)
+	if err != nil {
+		return nil, fmt.Errorf("server.http: %w", err)
+	}
+	if tc != nil {
+		opts = append(opts, http.TLSConfig(tc))
+	}
 
-	return http.NewServer(opts...)
+	return http.NewServer(opts...), nil
 
 }
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/overload"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// NewShedder 根据 conf.Server.Overload 创建过载保护，未启用时返回 nil
+// HTTP 与 gRPC 服务共用同一份统计
+func NewShedder(c *conf.Config) (*overload.Shedder, error) {
+	oc := c.GetServer().GetOverload()
+	if !oc.GetEnable() {
+		return nil, nil
+	}
+	return overload.New(oc)
+}
+
+// shedder 过载保护中间件，未启用时直接放行
+func shedder(s *overload.Shedder, logger log.Logger) middleware.Middleware {
+	if s == nil {
+		return func(handler middleware.Handler) middleware.Handler { return handler }
+	}
+	return overload.Server(s, logger)
+}
@@ -0,0 +1,16 @@
+package server
+
+import (
+	"{{cookiecutter.project_name}}/pkg/ratelimit"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// rateLimiter 限流中间件，未启用限流时直接放行
+func rateLimiter(rl *ratelimit.RateLimiter, logger log.Logger) middleware.Middleware {
+	if rl == nil {
+		return func(handler middleware.Handler) middleware.Handler { return handler }
+	}
+	return ratelimit.Server(rl, logger)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"{{cookiecutter.project_name}}/api/v1/captcha"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+)
+
+func init() {
+	response.RegisterReason(captcha.ErrorReason_CAPTCHA_GENERATE_FAILED.String(), response.CaptchaFailed)
+	response.RegisterReason(captcha.ErrorReason_CAPTCHA_INVALID.String(), response.CaptchaVerifyFailed)
+}
+
+// CaptchaService is a captcha service.
+type CaptchaService struct {
+	captcha.UnimplementedCaptchaServer
+
+	uc *biz.CaptchaUsecase
+}
+
+// NewCaptchaService new a captcha service.
+func NewCaptchaService(uc *biz.CaptchaUsecase) *CaptchaService {
+	return &CaptchaService{uc: uc}
+}
+
+// GetCaptcha implements captcha.CaptchaServer.
+func (s *CaptchaService) GetCaptcha(ctx context.Context, in *captcha.GetCaptchaRequest) (*captcha.GetCaptchaReply, error) {
+	c, err := s.uc.Generate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &captcha.GetCaptchaReply{CaptchaId: c.ID, Image: c.Image, ExpiresIn: int64(c.Expire.Seconds())}, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+
+	"{{cookiecutter.project_name}}/api/v1/common"
+	"{{cookiecutter.project_name}}/api/v1/dict"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	response.RegisterReason(dict.ErrorReason_DICT_NOT_FOUND.String(), response.GetDictListFailed)
+	response.RegisterReason(dict.ErrorReason_GET_DICT_FAILED.String(), response.GetDictListFailed)
+}
+
+// DictService is the data dictionary service.
+type DictService struct {
+	dict.UnimplementedDictServer
+
+	uc *biz.DictUsecase
+}
+
+// NewDictService new a data dictionary service.
+func NewDictService(uc *biz.DictUsecase) *DictService {
+	return &DictService{uc: uc}
+}
+
+// ListDicts implements dict.DictServer.
+func (s *DictService) ListDicts(ctx context.Context, in *dict.ListDictsRequest) (*dict.ListDictsReply, error) {
+	result, err := s.uc.ListDict(ctx, in.GetPage())
+	if err != nil {
+		return nil, err
+	}
+	reply := &dict.ListDictsReply{
+		Dicts: make([]*dict.DictInfo, 0, len(result.Items)),
+		Page:  &common.PageReply{NextPageToken: result.NextPageToken, TotalSize: result.TotalSize},
+	}
+	for _, d := range result.Items {
+		reply.Dicts = append(reply.Dicts, toDictInfo(d))
+	}
+	return reply, nil
+}
+
+// GetDict implements dict.DictServer.
+func (s *DictService) GetDict(ctx context.Context, in *dict.GetDictRequest) (*dict.DictInfo, error) {
+	d, err := s.uc.GetDict(ctx, in.Code, in.IncludeDisabled)
+	if err != nil {
+		return nil, err
+	}
+	return toDictInfo(d), nil
+}
+
+// CreateDict implements dict.DictServer.
+func (s *DictService) CreateDict(ctx context.Context, in *dict.CreateDictRequest) (*dict.DictInfo, error) {
+	d, err := s.uc.CreateDict(ctx, toDict(in.GetDict()))
+	if err != nil {
+		return nil, err
+	}
+	return toDictInfo(d), nil
+}
+
+// UpdateDict implements dict.DictServer.
+func (s *DictService) UpdateDict(ctx context.Context, in *dict.UpdateDictRequest) (*dict.DictInfo, error) {
+	d, err := s.uc.UpdateDict(ctx, toDict(in.GetDict()))
+	if err != nil {
+		return nil, err
+	}
+	return toDictInfo(d), nil
+}
+
+// DeleteDict implements dict.DictServer.
+func (s *DictService) DeleteDict(ctx context.Context, in *dict.DeleteDictRequest) (*dict.DeleteDictReply, error) {
+	if err := s.uc.DeleteDict(ctx, in.Code); err != nil {
+		return nil, err
+	}
+	return &dict.DeleteDictReply{}, nil
+}
+
+func toDict(in *dict.DictInfo) *biz.Dict {
+	d := &biz.Dict{Code: in.GetCode(), Name: in.GetName(), Description: in.GetDescription(), Version: in.GetVersion()}
+	d.Items = make([]*biz.DictItem, 0, len(in.GetItems()))
+	for _, item := range in.GetItems() {
+		d.Items = append(d.Items, &biz.DictItem{
+			Value:    item.GetValue(),
+			Label:    item.GetLabel(),
+			Sort:     item.GetSort(),
+			Disabled: item.GetDisabled(),
+			Extra:    item.GetExtra(),
+		})
+	}
+	return d
+}
+
+func toDictInfo(d *biz.Dict) *dict.DictInfo {
+	info := &dict.DictInfo{
+		Code:        d.Code,
+		Name:        d.Name,
+		Description: d.Description,
+		Version:     d.Version,
+		UpdatedAt:   timestamppb.New(d.UpdatedAt),
+	}
+	for _, item := range d.Items {
+		info.Items = append(info.Items, &dict.DictItem{
+			Value:    item.Value,
+			Label:    item.Label,
+			Sort:     item.Sort,
+			Disabled: item.Disabled,
+			Extra:    item.Extra,
+		})
+	}
+	return info
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	nethttp "net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/common"
+	"{{cookiecutter.project_name}}/api/v1/file"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/authz"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"{{cookiecutter.project_name}}/pkg/storage"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// OperationFileUploadFile is the operation of the multipart upload, used by authz policies and the auth allowList.
+const OperationFileUploadFile = "/v1.file.File/UploadFile"
+
+// uploadFormOverhead is the size allowed for the multipart boundaries, headers and other fields.
+const uploadFormOverhead = 1 << 20
+
+func init() {
+	response.RegisterReason(file.ErrorReason_FILE_MISSING.String(), response.FileReportFailed)
+	response.RegisterReason(file.ErrorReason_FILE_TOO_LARGE.String(), response.FileReportFailed)
+	response.RegisterReason(file.ErrorReason_FILE_TYPE_NOT_ALLOWED.String(), response.FileReportFailed)
+	response.RegisterReason(file.ErrorReason_FILE_CHECKSUM_MISMATCH.String(), response.FileReportFailed)
+	response.RegisterReason(file.ErrorReason_UPLOAD_FILE_FAILED.String(), response.FileReportFailed)
+	response.RegisterReason(file.ErrorReason_FILE_NOT_FOUND.String(), response.FileOpenFailed)
+	response.RegisterReason(file.ErrorReason_GET_FILE_FAILED.String(), response.FileOpenFailed)
+	response.RegisterReason(file.ErrorReason_OPEN_FILE_FAILED.String(), response.FileOpenFailed)
+	response.RegisterReason(file.ErrorReason_DOWNLOAD_URL_INVALID.String(), response.FileOpenFailed)
+}
+
+// FileService is the file upload service.
+type FileService struct {
+	file.UnimplementedFileServer
+
+	uc   *biz.FileUsecase
+	conf *biz.FileConfig
+	az   *authz.Authorizer
+}
+
+// NewFileService new a file upload service, az is nil when authz is disabled.
+func NewFileService(uc *biz.FileUsecase, conf *biz.FileConfig, az *authz.Authorizer) *FileService {
+	return &FileService{uc: uc, conf: conf, az: az}
+}
+
+// UploadFile handles POST /api/v1/files, a multipart/form-data request whose "file" part is streamed to the storage.
+// Fields before the file part are skipped, an optional sha256 query parameter is verified against the content.
+// The request goes through the server middleware as OperationFileUploadFile.
+func (s *FileService) UploadFile(ctx http.Context) error {
+	http.SetOperation(ctx, OperationFileUploadFile)
+	h := ctx.Middleware(func(c context.Context, _ any) (any, error) {
+		return s.upload(c, ctx)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *FileService) upload(ctx context.Context, hc http.Context) (*file.FileInfo, error) {
+	req := hc.Request()
+	req.Body = nethttp.MaxBytesReader(hc.Response(), req.Body, s.conf.MaxSize+uploadFormOverhead)
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, file.ErrorFileMissing("not a multipart request: %v", err)
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, file.ErrorFileMissing("no file part in the request")
+		}
+		if err != nil {
+			var maxErr *nethttp.MaxBytesError
+			if errors.As(err, &maxErr) {
+				return nil, biz.ErrFileTooLarge
+			}
+			return nil, file.ErrorFileMissing("read multipart: %v", err)
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			continue
+		}
+		// 上传耗时取决于文件大小与客户端网速，不受服务端请求超时限制
+		uctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.conf.UploadTimeout)
+		defer cancel()
+		f, err := s.uc.Upload(uctx, auth.Subject(ctx), &biz.FileUpload{
+			Name:   part.FileName(),
+			SHA256: hc.Query().Get("sha256"),
+			Body:   part,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return toFileInfo(f), nil
+	}
+}
+
+// DownloadFile handles GET /api/v1/files/download/{key}, the presigned URLs of the local storage.
+// The signature authenticates the request, so it does not go through the server middleware.
+func (s *FileService) DownloadFile(ctx http.Context) error {
+	key := ctx.Vars().Get("key")
+	rc, err := s.uc.OpenSigned(ctx, key, ctx.Query())
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// 上传时仅在扩展名与检测出的类型一致时保留扩展名，其余文件按二进制下载
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w := ctx.Response()
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", storage.ContentDisposition(ctx.Query().Get("filename")))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		nethttp.ServeContent(w, ctx.Request(), "", time.Time{}, rs)
+		return nil
+	}
+	_, _ = io.Copy(w, rc)
+	return nil
+}
+
+// ListFiles implements file.FileServer.
+func (s *FileService) ListFiles(ctx context.Context, in *file.ListFilesRequest) (*file.ListFilesReply, error) {
+	result, err := s.uc.ListFile(ctx, auth.Subject(ctx), in.GetPage())
+	if err != nil {
+		return nil, err
+	}
+	reply := &file.ListFilesReply{
+		Files: make([]*file.FileInfo, 0, len(result.Items)),
+		Page:  &common.PageReply{NextPageToken: result.NextPageToken, TotalSize: result.TotalSize},
+	}
+	for _, f := range result.Items {
+		reply.Files = append(reply.Files, toFileInfo(f))
+	}
+	return reply, nil
+}
+
+// GetFile implements file.FileServer.
+func (s *FileService) GetFile(ctx context.Context, in *file.GetFileRequest) (*file.FileInfo, error) {
+	id, err := parseFileID(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.uc.GetFile(ctx, s.fileOwner(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	return toFileInfo(f), nil
+}
+
+// GetDownloadURL implements file.FileServer.
+func (s *FileService) GetDownloadURL(ctx context.Context, in *file.GetDownloadURLRequest) (*file.DownloadURL, error) {
+	id, err := parseFileID(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	u, expiresAt, err := s.uc.DownloadURL(ctx, s.fileOwner(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	return &file.DownloadURL{Url: u, ExpiresAt: timestamppb.New(expiresAt)}, nil
+}
+
+// DeleteFile implements file.FileServer.
+func (s *FileService) DeleteFile(ctx context.Context, in *file.DeleteFileRequest) (*file.DeleteFileReply, error) {
+	id, err := parseFileID(in.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.uc.DeleteFile(ctx, s.fileOwner(ctx), id); err != nil {
+		return nil, err
+	}
+	return &file.DeleteFileReply{}, nil
+}
+
+// fileOwner 未认证的请求只能访问匿名上传的文件，管理员可访问全部文件
+func (s *FileService) fileOwner(ctx context.Context) biz.FileOwner {
+	return biz.FileOwner{User: auth.Subject(ctx), Admin: s.az != nil && s.az.IsAdmin(ctx)}
+}
+
+func parseFileID(id string) (int64, error) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, biz.ErrFileNotFound
+	}
+	return n, nil
+}
+
+func toFileInfo(f *biz.File) *file.FileInfo {
+	return &file.FileInfo{
+		Id:          strconv.FormatInt(f.ID, 10),
+		Name:        f.Name,
+		Size:        f.Size,
+		ContentType: f.ContentType,
+		Sha256:      f.SHA256,
+		CreatedAt:   timestamppb.New(f.CreatedAt),
+	}
+}
@@ -2,11 +2,31 @@ package service
 
 import (
 	"context"
+	"strconv"
 
+	"{{cookiecutter.project_name}}/api/v1/common"
 	v1 "{{cookiecutter.project_name}}/api/v1/helloworld"
 	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/fieldmask"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"{{cookiecutter.project_name}}/pkg/query"
+	"{{cookiecutter.project_name}}/pkg/sheet"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Operations of the sheet import and export, used by authz policies and the auth allowList.
+const (
+	OperationGreeterImportUsers = "/v1.helloworld.Greeter/ImportUsers"
+	OperationGreeterExportUsers = "/v1.helloworld.Greeter/ExportUsers"
 )
 
+func init() {
+	response.RegisterReason(v1.ErrorReason_IMPORT_FILE_INVALID.String(), response.FileWithExcelFailed)
+}
+
 // GreeterService is a greeter service.
 type GreeterService struct {
 	v1.UnimplementedGreeterServer
@@ -21,9 +41,233 @@ func NewGreeterService(uc *biz.GreeterUsecase) *GreeterService {
 
 // SayHello implements helloworld.GreeterServer.
 func (s *GreeterService) SayHello(ctx context.Context, in *v1.HelloRequest) (*v1.HelloReply, error) {
-	g, err := s.uc.CreateGreeter(ctx, &biz.Greeter{Hello: in.Name})
+	g := s.uc.SayHello(ctx, in.Name)
+	return &v1.HelloReply{Message: "Hello " + g.Hello}, nil
+}
+
+// AddUser implements helloworld.GreeterServer.
+func (s *GreeterService) AddUser(ctx context.Context, in *v1.AddUserRequest) (*v1.AddUserResponse, error) {
+	g := &biz.Greeter{
+		Hello: in.Username,
+		Age:   in.Age,
+		Order: in.Order,
+		Hobby: in.Hobby,
+	}
+	if in.Birthday != nil {
+		g.Birthday = in.Birthday.AsTime()
+	}
+	g, err := s.uc.CreateGreeter(ctx, g)
 	if err != nil {
 		return nil, err
 	}
-	return &v1.HelloReply{Message: "Hello " + g.Hello}, nil
+	return &v1.AddUserResponse{Id: strconv.FormatInt(g.ID, 10)}, nil
+}
+
+// GetUser implements helloworld.GreeterServer.
+func (s *GreeterService) GetUser(ctx context.Context, in *v1.GetUserRequest) (*v1.User, error) {
+	id, err := strconv.ParseInt(in.Id, 10, 64)
+	if err != nil {
+		return nil, v1.ErrorInvalidUserId("invalid user id: %s", in.Id)
+	}
+	if err := validateMask(in.ReadMask); err != nil {
+		return nil, err
+	}
+	g, err := s.uc.GetGreeter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	u := toUser(g)
+	fieldmask.Prune(u, in.ReadMask)
+	return u, nil
+}
+
+// ListUser implements helloworld.GreeterServer.
+func (s *GreeterService) ListUser(ctx context.Context, in *v1.ListUserRequest) (*v1.ListUserReply, error) {
+	if err := validateMask(in.ReadMask); err != nil {
+		return nil, err
+	}
+	page := in.GetPage()
+	if in.Username != "" {
+		// username 参数与 filter 同时生效
+		filter := "username = " + query.Quote(in.Username)
+		if f := page.GetFilter(); f != "" {
+			filter = "(" + f + ") AND " + filter
+		}
+		page = &common.PageRequest{
+			PageSize:  page.GetPageSize(),
+			PageToken: page.GetPageToken(),
+			OrderBy:   page.GetOrderBy(),
+			Filter:    filter,
+		}
+	}
+	result, err := s.uc.ListGreeter(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	reply := &v1.ListUserReply{
+		Users: make([]*v1.User, 0, len(result.Items)),
+		Page:  &common.PageReply{NextPageToken: result.NextPageToken, TotalSize: result.TotalSize},
+	}
+	for _, g := range result.Items {
+		u := toUser(g)
+		fieldmask.Prune(u, in.ReadMask)
+		reply.Users = append(reply.Users, u)
+	}
+	return reply, nil
+}
+
+// UpdateUser implements helloworld.GreeterServer.
+func (s *GreeterService) UpdateUser(ctx context.Context, in *v1.UpdateUserRequest) (*v1.User, error) {
+	id, err := strconv.ParseInt(in.GetUser().GetId(), 10, 64)
+	if err != nil {
+		return nil, v1.ErrorInvalidUserId("invalid user id: %s", in.GetUser().GetId())
+	}
+	if err := validateMask(in.UpdateMask); err != nil {
+		return nil, err
+	}
+	u := in.GetUser()
+	g := &biz.Greeter{
+		ID:    id,
+		Hello: u.Username,
+		Age:   u.Age,
+		Order: u.Order,
+		Hobby: u.Hobby,
+	}
+	if u.Birthday != nil {
+		g.Birthday = u.Birthday.AsTime()
+	}
+	g, err = s.uc.UpdateGreeter(ctx, g, fieldmask.Paths(in.UpdateMask))
+	if err != nil {
+		return nil, err
+	}
+	return toUser(g), nil
+}
+
+// ImportUsers handles POST /api/v1/greeter/users/import, a multipart/form-data request whose "file" part is a
+// .csv or .xlsx sheet with the columns of AddUserRequest. Each row is validated and added as AddUser does,
+// failed rows are listed in the report and do not stop the import, rows added before an aborted import are kept.
+// The request goes through the server middleware as OperationGreeterImportUsers.
+func (s *GreeterService) ImportUsers(ctx http.Context) error {
+	http.SetOperation(ctx, OperationGreeterImportUsers)
+	h := ctx.Middleware(func(c context.Context, _ any) (any, error) {
+		return s.importUsers(c, ctx)
+	})
+	out, err := h(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, out)
+}
+
+func (s *GreeterService) importUsers(ctx context.Context, hc http.Context) (*common.ImportReport, error) {
+	r, err := openSheet(hc)
+	if err != nil {
+		return nil, v1.ErrorImportFileInvalid("open import file: %v", err)
+	}
+	defer r.Close()
+	cols, err := sheet.Columns(&v1.AddUserRequest{})
+	if err != nil {
+		return nil, err
+	}
+	dec, err := sheet.NewDecoder(r, cols, sheet.WithMaxRows(maxImportRows))
+	if err != nil {
+		return nil, v1.ErrorImportFileInvalid("read header: %v", err)
+	}
+	// 导入耗时取决于行数，不受服务端请求超时限制
+	ictx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sheetTimeout)
+	defer cancel()
+	report, err := sheet.Import(ictx, dec, func(ctx context.Context, in *v1.AddUserRequest) error {
+		_, err := s.AddUser(ctx, in)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toImportReport(report), nil
+}
+
+// ExportUsers handles GET /api/v1/greeter/users/export, it takes the query parameters of ListUser and streams
+// every matching user as a sheet, format is "xlsx" (default) or "csv" and readMask selects the columns.
+// The request goes through the server middleware as OperationGreeterExportUsers.
+func (s *GreeterService) ExportUsers(ctx http.Context) error {
+	var in v1.ListUserRequest
+	if err := ctx.BindQuery(&in); err != nil {
+		return err
+	}
+	http.SetOperation(ctx, OperationGreeterExportUsers)
+	out := &sheetExport{hc: ctx}
+	h := ctx.Middleware(func(c context.Context, req any) (any, error) {
+		return nil, s.exportUsers(c, out, req.(*v1.ListUserRequest))
+	})
+	_, err := h(ctx, &in)
+	return out.finish(err)
+}
+
+func (s *GreeterService) exportUsers(ctx context.Context, out *sheetExport, in *v1.ListUserRequest) error {
+	format, err := sheet.ParseFormat(out.hc.Query().Get("format"))
+	if err != nil {
+		return biz.InvalidQuery(err)
+	}
+	if err := validateMask(in.ReadMask); err != nil {
+		return err
+	}
+	cols, err := sheet.Columns(&v1.User{}, fieldmask.Paths(in.ReadMask)...)
+	if err != nil {
+		return biz.InvalidQuery(err)
+	}
+	// 导出耗时取决于行数，不受服务端请求超时限制
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sheetTimeout)
+	defer cancel()
+	req := &v1.ListUserRequest{
+		Username: in.Username,
+		Page: &common.PageRequest{
+			PageSize: exportPageSize,
+			OrderBy:  in.GetPage().GetOrderBy(),
+			Filter:   in.GetPage().GetFilter(),
+		},
+		ReadMask: in.ReadMask,
+	}
+	// 先读取第一页，查询参数错误时仍可返回错误响应
+	reply, err := s.ListUser(ctx, req)
+	if err != nil {
+		return err
+	}
+	return out.write("users", format, cols, func(enc *sheet.Encoder) error {
+		for {
+			for _, u := range reply.Users {
+				if err := enc.Encode(u); err != nil {
+					return err
+				}
+			}
+			if reply.GetPage().GetNextPageToken() == "" {
+				return nil
+			}
+			req.Page.PageToken = reply.GetPage().GetNextPageToken()
+			if reply, err = s.ListUser(ctx, req); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// validateMask 校验字段掩码中的路径均为 User 的字段
+func validateMask(mask *fieldmaskpb.FieldMask) error {
+	if err := fieldmask.Validate(mask, (*v1.User)(nil)); err != nil {
+		return biz.InvalidQuery(err)
+	}
+	return nil
+}
+
+func toUser(g *biz.Greeter) *v1.User {
+	u := &v1.User{
+		Id:       strconv.FormatInt(g.ID, 10),
+		Username: g.Hello,
+		Age:      g.Age,
+		Order:    g.Order,
+		Hobby:    g.Hobby,
+	}
+	if !g.Birthday.IsZero() {
+		u.Birthday = timestamppb.New(g.Birthday)
+	}
+	return u
 }
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+
+	"{{cookiecutter.project_name}}/api/v1/admin"
+	"{{cookiecutter.project_name}}/api/v1/common"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/authz"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+)
+
+func init() {
+	response.RegisterReason(admin.ErrorReason_NOT_ADMIN.String(), response.NotAdminID)
+	response.RegisterReason(admin.ErrorReason_POLICY_ADD_FAILED.String(), response.CasbinAddFailed)
+	response.RegisterReason(admin.ErrorReason_POLICY_DELETE_FAILED.String(), response.CasbinDelFailed)
+	response.RegisterReason(admin.ErrorReason_POLICY_UPDATE_FAILED.String(), response.CasbinUpdateFailed)
+	response.RegisterReason(admin.ErrorReason_POLICY_LIST_FAILED.String(), response.CasbinListFailed)
+}
+
+// PolicyService is the policy admin service, only users with the admin role may call it.
+type PolicyService struct {
+	admin.UnimplementedPolicyServer
+
+	uc *biz.PolicyUsecase
+	az *authz.Authorizer
+}
+
+// NewPolicyService new a policy admin service, az is nil when authz is disabled.
+func NewPolicyService(uc *biz.PolicyUsecase, az *authz.Authorizer) *PolicyService {
+	return &PolicyService{uc: uc, az: az}
+}
+
+// ListPolicies implements admin.PolicyServer.
+func (s *PolicyService) ListPolicies(ctx context.Context, in *admin.ListPoliciesRequest) (*admin.ListPoliciesReply, error) {
+	if err := s.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	result, err := s.uc.ListPolicy(ctx, in.GetPage())
+	if err != nil {
+		return nil, err
+	}
+	reply := &admin.ListPoliciesReply{
+		Rules: make([]*admin.PolicyRule, 0, len(result.Items)),
+		Page:  &common.PageReply{NextPageToken: result.NextPageToken, TotalSize: result.TotalSize},
+	}
+	for _, p := range result.Items {
+		reply.Rules = append(reply.Rules, toPolicyRule(p))
+	}
+	return reply, nil
+}
+
+// AddPolicy implements admin.PolicyServer.
+func (s *PolicyService) AddPolicy(ctx context.Context, in *admin.AddPolicyRequest) (*admin.PolicyRule, error) {
+	if err := s.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	p, err := s.uc.AddPolicy(ctx, toPolicy(in.GetRule()))
+	if err != nil {
+		return nil, err
+	}
+	return toPolicyRule(p), nil
+}
+
+// UpdatePolicy implements admin.PolicyServer.
+func (s *PolicyService) UpdatePolicy(ctx context.Context, in *admin.UpdatePolicyRequest) (*admin.PolicyRule, error) {
+	if err := s.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	p, err := s.uc.UpdatePolicy(ctx, toPolicy(in.GetOldRule()), toPolicy(in.GetNewRule()))
+	if err != nil {
+		return nil, err
+	}
+	return toPolicyRule(p), nil
+}
+
+// DeletePolicy implements admin.PolicyServer.
+func (s *PolicyService) DeletePolicy(ctx context.Context, in *admin.DeletePolicyRequest) (*admin.DeletePolicyReply, error) {
+	if err := s.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.uc.DeletePolicy(ctx, toPolicy(in.GetRule())); err != nil {
+		return nil, err
+	}
+	return &admin.DeletePolicyReply{}, nil
+}
+
+// ReloadPolicies implements admin.PolicyServer.
+func (s *PolicyService) ReloadPolicies(ctx context.Context, in *admin.ReloadPoliciesRequest) (*admin.ReloadPoliciesReply, error) {
+	if err := s.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	n, err := s.uc.ReloadPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &admin.ReloadPoliciesReply{TotalSize: n}, nil
+}
+
+// checkAdmin 仅允许拥有管理员角色的用户调用，未启用鉴权时一律拒绝
+func (s *PolicyService) checkAdmin(ctx context.Context) error {
+	if s.az == nil {
+		return admin.ErrorNotAdmin("authz is not enabled")
+	}
+	if !s.az.IsAdmin(ctx) {
+		return admin.ErrorNotAdmin("admin role is required")
+	}
+	return nil
+}
+
+func toPolicy(r *admin.PolicyRule) *biz.Policy {
+	return &biz.Policy{Ptype: r.GetPtype(), Subject: r.GetSubject(), Object: r.GetObject(), Action: r.GetAction()}
+}
+
+func toPolicyRule(p *biz.Policy) *admin.PolicyRule {
+	return &admin.PolicyRule{Ptype: p.Ptype, Subject: p.Subject, Object: p.Object, Action: p.Action}
+}
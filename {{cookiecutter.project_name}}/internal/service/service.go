@@ -3,4 +3,5 @@ package service
 import "github.com/google/wire"
 
 // ProviderSet is service providers.
-var ProviderSet = wire.NewSet(NewServiceHolder, NewGreeterService)
+var ProviderSet = wire.NewSet(NewServiceHolder, NewGreeterService, NewPolicyService, NewCaptchaService, NewUserService, NewDictService, NewSettingsService,
+	NewFileService)
@@ -1,11 +1,23 @@
 package service
 
 type Holder struct {
-	GreeterService *GreeterService
+	GreeterService  *GreeterService
+	PolicyService   *PolicyService
+	CaptchaService  *CaptchaService
+	UserService     *UserService
+	DictService     *DictService
+	SettingsService *SettingsService
+	FileService     *FileService
 }
 
-func NewServiceHolder(gs *GreeterService) *Holder {
+func NewServiceHolder(gs *GreeterService, ps *PolicyService, cs *CaptchaService, us *UserService, ds *DictService, ss *SettingsService, fs *FileService) *Holder {
 	return &Holder{
-		GreeterService: gs,
+		GreeterService:  gs,
+		PolicyService:   ps,
+		CaptchaService:  cs,
+		UserService:     us,
+		DictService:     ds,
+		SettingsService: ss,
+		FileService:     fs,
 	}
 }
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+
+	"{{cookiecutter.project_name}}/api/v1/settings"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/authz"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	response.RegisterReason(settings.ErrorReason_GET_SETTINGS_FAILED.String(), response.GetSettingsFailed)
+	response.RegisterReason(settings.ErrorReason_UPDATE_SETTINGS_FAILED.String(), response.UpdateSettingsFailed)
+	response.RegisterReason(settings.ErrorReason_SETTINGS_VERSION_CONFLICT.String(), response.UpdateSettingsFailed)
+}
+
+// SettingsService is the application settings service.
+type SettingsService struct {
+	settings.UnimplementedSettingsServer
+
+	uc *biz.SettingsUsecase
+	az *authz.Authorizer
+}
+
+// NewSettingsService new an application settings service, az is nil when authz is disabled.
+func NewSettingsService(uc *biz.SettingsUsecase, az *authz.Authorizer) *SettingsService {
+	return &SettingsService{uc: uc, az: az}
+}
+
+// GetSettings implements settings.SettingsServer.
+func (s *SettingsService) GetSettings(ctx context.Context, in *settings.GetSettingsRequest) (*settings.SettingsInfo, error) {
+	st, err := s.uc.GetSettings(ctx, settingsOwner(ctx), in.Scope, in.Name)
+	if err != nil {
+		return nil, err
+	}
+	return toSettingsInfo(st)
+}
+
+// UpdateSettings implements settings.SettingsServer.
+func (s *SettingsService) UpdateSettings(ctx context.Context, in *settings.UpdateSettingsRequest) (*settings.SettingsInfo, error) {
+	scope, err := s.writableScope(ctx, in.Scope)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.uc.UpdateSettings(ctx, settingsOwner(ctx), &biz.Settings{
+		Scope:   scope,
+		Name:    in.Name,
+		Value:   in.GetValue().AsMap(),
+		Version: in.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSettingsInfo(st)
+}
+
+// DeleteSettings implements settings.SettingsServer.
+func (s *SettingsService) DeleteSettings(ctx context.Context, in *settings.DeleteSettingsRequest) (*settings.DeleteSettingsReply, error) {
+	scope, err := s.writableScope(ctx, in.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.uc.DeleteSettings(ctx, settingsOwner(ctx), scope, in.Name); err != nil {
+		return nil, err
+	}
+	return &settings.DeleteSettingsReply{}, nil
+}
+
+// writableScope 默认写入 user 作用域，global 与 tenant 仅管理员可写
+func (s *SettingsService) writableScope(ctx context.Context, scope string) (string, error) {
+	switch scope {
+	case "", biz.SettingsScopeUser:
+		return biz.SettingsScopeUser, nil
+	default:
+		if s.az == nil || !s.az.IsAdmin(ctx) {
+			return "", settings.ErrorSettingsScopeDenied("admin role is required to write %s settings", scope)
+		}
+		return scope, nil
+	}
+}
+
+// settingsOwner 从 token 中取出租户与用户，未认证的请求只能读取 global 作用域
+func settingsOwner(ctx context.Context) biz.SettingsOwner {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return biz.SettingsOwner{}
+	}
+	return biz.SettingsOwner{Tenant: claims.Tenant, User: claims.Subject}
+}
+
+func toSettingsInfo(s *biz.Settings) (*settings.SettingsInfo, error) {
+	value, err := structpb.NewStruct(s.Value)
+	if err != nil {
+		return nil, settings.ErrorGetSettingsFailed("encode settings: %v", err).WithCause(err)
+	}
+	info := &settings.SettingsInfo{Name: s.Name, Scope: s.Scope, Value: value, Version: s.Version}
+	if !s.UpdatedAt.IsZero() {
+		info.UpdatedAt = timestamppb.New(s.UpdatedAt)
+	}
+	return info, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"time"
+
+	"{{cookiecutter.project_name}}/api/v1/common"
+	"{{cookiecutter.project_name}}/pkg/sheet"
+	"{{cookiecutter.project_name}}/pkg/storage"
+
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	// maxImportSize is the size limit of an import request.
+	maxImportSize = 10 << 20
+	// maxImportRows is the data row limit of an import file.
+	maxImportRows = 10000
+	// exportPageSize is the page size used to read the rows of an export.
+	exportPageSize = 100
+	// sheetTimeout bounds an import or export instead of the server timeout.
+	sheetTimeout = 10 * time.Minute
+)
+
+// openSheet returns a reader of the "file" part of a multipart/form-data import request,
+// the format is taken from the extension of the file name.
+func openSheet(hc http.Context) (sheet.Reader, error) {
+	req := hc.Request()
+	req.Body = nethttp.MaxBytesReader(hc.Response(), req.Body, maxImportSize)
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("not a multipart request: %w", err)
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no file part in the request")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read multipart: %w", err)
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			continue
+		}
+		format, err := sheet.FormatOf(part.FileName())
+		if err != nil {
+			return nil, err
+		}
+		return sheet.NewReader(part, format)
+	}
+}
+
+// sheetExport writes a sheet to the response of an export request.
+type sheetExport struct {
+	hc http.Context
+	// started is set once the response is written, later errors can only abort it.
+	started bool
+}
+
+// write sends the response headers and the header row, then fill encodes the rows.
+func (e *sheetExport) write(name string, format sheet.Format, cols []sheet.Column, fill func(*sheet.Encoder) error) error {
+	w := e.hc.Response()
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", storage.ContentDisposition(name+"."+string(format)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	e.started = true
+	sw, err := sheet.NewWriter(w, format)
+	if err != nil {
+		return err
+	}
+	enc, err := sheet.NewEncoder(sw, cols)
+	if err == nil {
+		err = fill(enc)
+	}
+	if cerr := sw.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// finish returns err of an export that has not started, the response can not carry an error once started.
+func (e *sheetExport) finish(err error) error {
+	if err != nil && e.started {
+		// 中断连接，客户端由此得知文件不完整
+		panic(nethttp.ErrAbortHandler)
+	}
+	return err
+}
+
+func toImportReport(r *sheet.Report) *common.ImportReport {
+	out := &common.ImportReport{
+		Total:     int32(r.Total),
+		Succeeded: int32(r.Succeeded),
+		Failed:    int32(r.Failed),
+		Errors:    make([]*common.RowError, 0, len(r.Errors)),
+	}
+	for _, e := range r.Errors {
+		out.Errors = append(out.Errors, &common.RowError{Row: int32(e.Row), Field: e.Field, Message: e.Message})
+	}
+	return out
+}
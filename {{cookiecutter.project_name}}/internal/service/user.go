@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"{{cookiecutter.project_name}}/api/v1/user"
+	"{{cookiecutter.project_name}}/internal/biz"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/fieldmask"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	response.RegisterReason(user.ErrorReason_LOGIN_FAILED.String(), response.LoginFailed)
+	response.RegisterReason(user.ErrorReason_ACCOUNT_DISABLED.String(), response.LoginFailed)
+	response.RegisterReason(user.ErrorReason_REGISTER_FAILED.String(), response.RegisterFailed)
+	response.RegisterReason(user.ErrorReason_USERNAME_TAKEN.String(), response.RegisterFailed)
+	response.RegisterReason(user.ErrorReason_ACCOUNT_NOT_FOUND.String(), response.GetUserInfoFailed)
+	response.RegisterReason(user.ErrorReason_GET_USER_INFO_FAILED.String(), response.GetUserInfoFailed)
+	response.RegisterReason(user.ErrorReason_UPDATE_USER_INFO_FAILED.String(), response.UpdateUserInfoFailed)
+	response.RegisterReason(user.ErrorReason_UPDATE_PASSWORD_FAILED.String(), response.UpdatePasswordFailed)
+	response.RegisterReason(user.ErrorReason_WRONG_PASSWORD.String(), response.UpdatePasswordFailed)
+	response.RegisterReason(user.ErrorReason_REFRESH_TOKEN_INVALID.String(), response.TokenFailed)
+}
+
+// UserService is the user account service.
+type UserService struct {
+	user.UnimplementedUserServer
+
+	uc *biz.UserUsecase
+}
+
+// NewUserService new a user account service.
+func NewUserService(uc *biz.UserUsecase) *UserService {
+	return &UserService{uc: uc}
+}
+
+// Register implements user.UserServer.
+func (s *UserService) Register(ctx context.Context, in *user.RegisterRequest) (*user.Profile, error) {
+	a, err := s.uc.Register(ctx, &biz.Account{Username: in.Username, Nickname: in.Nickname, Email: in.Email}, in.Password)
+	if err != nil {
+		return nil, err
+	}
+	return toProfile(a), nil
+}
+
+// Login implements user.UserServer.
+func (s *UserService) Login(ctx context.Context, in *user.LoginRequest) (*user.TokenReply, error) {
+	t, err := s.uc.Login(ctx, in.Username, in.Password, in.CaptchaId, in.Captcha)
+	if err != nil {
+		return nil, err
+	}
+	return toTokenReply(t), nil
+}
+
+// RefreshToken implements user.UserServer.
+func (s *UserService) RefreshToken(ctx context.Context, in *user.RefreshTokenRequest) (*user.TokenReply, error) {
+	t, err := s.uc.Refresh(ctx, in.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	return toTokenReply(t), nil
+}
+
+// Logout implements user.UserServer.
+func (s *UserService) Logout(ctx context.Context, in *user.LogoutRequest) (*user.LogoutReply, error) {
+	id, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.uc.Logout(ctx, id, in.RefreshToken); err != nil {
+		return nil, err
+	}
+	return &user.LogoutReply{}, nil
+}
+
+// GetProfile implements user.UserServer.
+func (s *UserService) GetProfile(ctx context.Context, in *user.GetProfileRequest) (*user.Profile, error) {
+	id, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.uc.GetProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toProfile(a), nil
+}
+
+// UpdateProfile implements user.UserServer.
+func (s *UserService) UpdateProfile(ctx context.Context, in *user.UpdateProfileRequest) (*user.Profile, error) {
+	id, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.uc.UpdateProfile(ctx, &biz.Account{ID: id, Nickname: in.Nickname, Email: in.Email}, fieldmask.Paths(in.UpdateMask))
+	if err != nil {
+		return nil, err
+	}
+	return toProfile(a), nil
+}
+
+// ChangePassword implements user.UserServer.
+func (s *UserService) ChangePassword(ctx context.Context, in *user.ChangePasswordRequest) (*user.ChangePasswordReply, error) {
+	id, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.uc.ChangePassword(ctx, id, in.OldPassword, in.NewPassword); err != nil {
+		return nil, err
+	}
+	return &user.ChangePasswordReply{}, nil
+}
+
+// currentUserID 返回 access token 中的用户 ID，接口在 allowList 中或 token 不是本模块签发时返回 401
+func currentUserID(ctx context.Context) (int64, error) {
+	sub := auth.Subject(ctx)
+	if sub == "" {
+		return 0, auth.ErrTokenMissing
+	}
+	id, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		return 0, auth.ErrTokenInvalid
+	}
+	return id, nil
+}
+
+func toProfile(a *biz.Account) *user.Profile {
+	return &user.Profile{
+		Id:        strconv.FormatInt(a.ID, 10),
+		Username:  a.Username,
+		Nickname:  a.Nickname,
+		Email:     a.Email,
+		Roles:     a.Roles,
+		CreatedAt: timestamppb.New(a.CreatedAt),
+	}
+}
+
+func toTokenReply(t *biz.Token) *user.TokenReply {
+	return &user.TokenReply{
+		AccessToken:      t.AccessToken,
+		RefreshToken:     t.RefreshToken,
+		ExpiresIn:        int64(t.AccessExpire.Seconds()),
+		RefreshExpiresIn: int64(t.RefreshExpire.Seconds()),
+		User:             toProfile(t.Account),
+	}
+}
@@ -1,10 +1,20 @@
 package main
 
 import (
+	"fmt"
 	cmd "{{cookiecutter.project_name}}/cmd/{{cookiecutter.project_name}}"
+	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := cmd.Migrate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app, f := cmd.NewApp()
 	defer f()
 	if err := app.Run(); err != nil {
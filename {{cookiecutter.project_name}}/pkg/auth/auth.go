@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 认证失败的错误原因
+const (
+	// ReasonTokenMissing 未携带 token，响应码为 response.TokenFailed
+	ReasonTokenMissing = "TOKEN_MISSING"
+	// ReasonTokenInvalid 签名、算法、签发者或受众不匹配，响应码为 response.TokenFailed
+	ReasonTokenInvalid = "TOKEN_INVALID"
+	// ReasonTokenExpired token 已过期或尚未生效，响应码为 response.TokenExpired
+	ReasonTokenExpired = "TOKEN_EXPIRED"
+	// ReasonTokenMalformed token 格式错误无法解析，响应码为 response.TokenValidateFailed
+	ReasonTokenMalformed = "TOKEN_MALFORMED"
+	// ReasonTokenRevoked token 已被吊销，如签发后用户修改了密码，响应码为 response.TokenExpired
+	ReasonTokenRevoked = "TOKEN_REVOKED"
+)
+
+// 认证失败的错误，HTTP 状态码均为 401
+var (
+	ErrTokenMissing   = errors.Unauthorized(ReasonTokenMissing, "token is missing")
+	ErrTokenInvalid   = errors.Unauthorized(ReasonTokenInvalid, "token is invalid")
+	ErrTokenExpired   = errors.Unauthorized(ReasonTokenExpired, "token has expired")
+	ErrTokenMalformed = errors.Unauthorized(ReasonTokenMalformed, "token is malformed")
+	ErrTokenRevoked   = errors.Unauthorized(ReasonTokenRevoked, "token has been revoked")
+)
+
+func init() {
+	response.RegisterReason(ReasonTokenMissing, response.TokenFailed)
+	response.RegisterReason(ReasonTokenInvalid, response.TokenFailed)
+	response.RegisterReason(ReasonTokenExpired, response.TokenExpired)
+	response.RegisterReason(ReasonTokenMalformed, response.TokenValidateFailed)
+	response.RegisterReason(ReasonTokenRevoked, response.TokenExpired)
+}
+
+// Claims token 中的声明，Subject 为用户标识
+type Claims struct {
+	jwt.RegisteredClaims
+	// Roles 用户角色，供鉴权使用
+	Roles []string `json:"roles,omitempty"`
+	// Tenant 多租户场景下用户所属的租户
+	Tenant string `json:"tenant,omitempty"`
+	// TokenVersion 签发时用户的凭证版本，版本递增后此前签发的 token 被吊销
+	TokenVersion int64 `json:"tv,omitempty"`
+}
+
+type claimsKey struct{}
+
+// NewContext 将声明放入 ctx
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext 获取认证中间件放入 ctx 的声明，未认证的请求返回 false
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+// Subject 返回当前请求的用户标识，未认证的请求返回空串
+func Subject(ctx context.Context) string {
+	if claims, ok := FromContext(ctx); ok {
+		return claims.Subject
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultClockSkew = 30 * time.Second
+	defaultExpire    = 2 * time.Hour
+)
+
+// JWT 按 conf.Auth.JWT 签发与校验 token
+type JWT struct {
+	method   jwt.SigningMethod
+	signKey  any
+	parser   *jwt.Parser
+	verify   jwt.Keyfunc
+	issuer   string
+	audience []string
+	expire   time.Duration
+	checks   []Check
+}
+
+// Check 签名与有效期校验通过后执行的检查，返回错误时拒绝 token，如吊销用户修改密码前签发的 token
+type Check func(ctx context.Context, claims *Claims) error
+
+// Option JWT 选项
+type Option func(*JWT)
+
+// WithCheck 添加 Verify 执行的检查
+func WithCheck(check Check) Option {
+	return func(j *JWT) {
+		if check != nil {
+			j.checks = append(j.checks, check)
+		}
+	}
+}
+
+// NewJWT 根据配置加载密钥，HS 系列使用 secret，其他算法使用 PEM 密钥文件
+func NewJWT(c *conf.Auth_JWT, opts ...Option) (*JWT, error) {
+	alg := c.GetAlgorithm()
+	if alg == "" {
+		alg = jwt.SigningMethodHS256.Alg()
+	}
+	method := jwt.GetSigningMethod(alg)
+	if method == nil || method == jwt.SigningMethodNone {
+		return nil, fmt.Errorf("auth: unsupported jwt algorithm %q", alg)
+	}
+
+	j := &JWT{
+		method:   method,
+		issuer:   c.GetIssuer(),
+		audience: c.GetAudience(),
+		expire:   defaultExpire,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	skew := defaultClockSkew
+	var err error
+	if s := c.GetClockSkew(); s != "" {
+		if skew, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("auth: invalid jwt clockSkew: %w", err)
+		}
+	}
+	if s := c.GetExpire(); s != "" {
+		if j.expire, err = time.ParseDuration(s); err != nil {
+			return nil, fmt.Errorf("auth: invalid jwt expire: %w", err)
+		}
+	}
+
+	var verifyKey any
+	if strings.HasPrefix(alg, "HS") {
+		if c.GetSecret() == "" {
+			return nil, errors.New("auth: jwt secret is required for " + alg)
+		}
+		verifyKey, j.signKey = []byte(c.GetSecret()), []byte(c.GetSecret())
+	} else {
+		if verifyKey, err = loadKey(c.GetPublicKeyFile(), alg, false); err != nil {
+			return nil, err
+		}
+		if c.GetPrivateKeyFile() != "" {
+			if j.signKey, err = loadKey(c.GetPrivateKeyFile(), alg, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+	j.verify = func(*jwt.Token) (any, error) { return verifyKey, nil }
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{alg}),
+		jwt.WithLeeway(skew),
+		jwt.WithExpirationRequired(),
+	}
+	if j.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(j.issuer))
+	}
+	if len(j.audience) > 0 {
+		parserOpts = append(parserOpts, jwt.WithAudience(j.audience...))
+	}
+	j.parser = jwt.NewParser(parserOpts...)
+	return j, nil
+}
+
+// Parse 校验 token 的签名与有效期并返回声明，失败时返回 ErrTokenXxx，不执行 WithCheck 添加的检查
+func (j *JWT) Parse(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := j.parser.ParseWithClaims(token, claims, j.verify)
+	switch {
+	case err == nil:
+		return claims, nil
+	case errors.Is(err, jwt.ErrTokenExpired), errors.Is(err, jwt.ErrTokenNotValidYet):
+		return nil, ErrTokenExpired.WithCause(err)
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return nil, ErrTokenMalformed.WithCause(err)
+	default:
+		return nil, ErrTokenInvalid.WithCause(err)
+	}
+}
+
+// Verify 校验 token 后依次执行 WithCheck 添加的检查，认证请求时应使用 Verify 而非 Parse
+func (j *JWT) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims, err := j.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	for _, check := range j.checks {
+		if err := check(ctx, claims); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// Sign 签发 token，未设置的签发者、受众、签发时间、过期时间与 ID 使用配置与默认值
+func (j *JWT) Sign(claims *Claims) (string, error) {
+	if j.signKey == nil {
+		return "", errors.New("auth: jwt privateKeyFile is required to sign tokens")
+	}
+	c := *claims
+	now := time.Now()
+	if c.Issuer == "" {
+		c.Issuer = j.issuer
+	}
+	if len(c.Audience) == 0 {
+		c.Audience = j.audience
+	}
+	if c.IssuedAt == nil {
+		c.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if c.ExpiresAt == nil {
+		c.ExpiresAt = jwt.NewNumericDate(now.Add(j.expire))
+	}
+	if c.ID == "" {
+		var b [16]byte
+		_, _ = rand.Read(b[:])
+		c.ID = hex.EncodeToString(b[:])
+	}
+	return jwt.NewWithClaims(j.method, &c).SignedString(j.signKey)
+}
+
+// Expire 签发 token 的有效期
+func (j *JWT) Expire() time.Duration {
+	return j.expire
+}
+
+func loadKey(file, alg string, private bool) (any, error) {
+	if file == "" {
+		return nil, fmt.Errorf("auth: jwt publicKeyFile is required for %s", alg)
+	}
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("auth: read jwt key: %w", err)
+	}
+	var key any
+	switch {
+	case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "PS"):
+		if private {
+			key, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+		} else {
+			key, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		}
+	case strings.HasPrefix(alg, "ES"):
+		if private {
+			key, err = jwt.ParseECPrivateKeyFromPEM(pem)
+		} else {
+			key, err = jwt.ParseECPublicKeyFromPEM(pem)
+		}
+	case alg == jwt.SigningMethodEdDSA.Alg():
+		if private {
+			key, err = jwt.ParseEdPrivateKeyFromPEM(pem)
+		} else {
+			key, err = jwt.ParseEdPublicKeyFromPEM(pem)
+		}
+	default:
+		return nil, fmt.Errorf("auth: unsupported jwt algorithm %q", alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("auth: parse jwt key %s: %w", file, err)
+	}
+	return key, nil
+}
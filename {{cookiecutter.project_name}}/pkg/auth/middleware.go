@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/selector"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const bearer = "Bearer"
+
+// Server JWT 认证中间件，通过 JWT.Verify 校验后将 Claims 放入 ctx
+// allowList 中的操作或 HTTP 路径跳过认证，以 * 结尾表示前缀匹配
+func Server(j *JWT, allowList ...string) middleware.Middleware {
+	allow := NewAllowList(allowList...)
+	return selector.Server(authenticate(j)).
+		Match(func(ctx context.Context, operation string) bool {
+			return !allow.Match(ctx, operation)
+		}).
+		Build()
+}
+
+func authenticate(j *JWT) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, ErrTokenMissing
+			}
+			scheme, token, ok := strings.Cut(tr.RequestHeader().Get("Authorization"), " ")
+			if !ok || !strings.EqualFold(scheme, bearer) || token == "" {
+				return nil, ErrTokenMissing
+			}
+			claims, err := j.Verify(ctx, strings.TrimSpace(token))
+			if err != nil {
+				return nil, err
+			}
+			return handler(NewContext(ctx, claims), req)
+		}
+	}
+}
+
+// AllowList 操作与 HTTP 路径名单，以 * 结尾表示前缀匹配
+type AllowList struct {
+	exact    map[string]struct{}
+	prefixes []string
+}
+
+// NewAllowList 创建名单
+func NewAllowList(patterns ...string) *AllowList {
+	a := &AllowList{exact: make(map[string]struct{})}
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			a.prefixes = append(a.prefixes, prefix)
+		} else if p != "" {
+			a.exact[p] = struct{}{}
+		}
+	}
+	return a
+}
+
+// Match 依次匹配 operation 与 HTTP 请求路径
+func (a *AllowList) Match(ctx context.Context, operation string) bool {
+	if a.contains(operation) {
+		return true
+	}
+	if r, ok := http.RequestFromServerContext(ctx); ok {
+		return a.contains(r.URL.Path)
+	}
+	return false
+}
+
+func (a *AllowList) contains(s string) bool {
+	if _, ok := a.exact[s]; ok {
+		return true
+	}
+	for _, prefix := range a.prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package authz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"{{cookiecutter.project_name}}/pkg/database"
+	"strings"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+)
+
+const (
+	ruleColumns = "ptype, v0, v1, v2, v3, v4, v5"
+	maxValues   = 6
+)
+
+// Adapter 将策略保存在 casbin_rule 表中，表结构见 internal/data/migrations
+type Adapter struct {
+	db *database.DB
+}
+
+var (
+	_ persist.Adapter          = (*Adapter)(nil)
+	_ persist.UpdatableAdapter = (*Adapter)(nil)
+)
+
+// NewAdapter 创建数据库策略存储
+func NewAdapter(db *database.DB) *Adapter {
+	return &Adapter{db: db}
+}
+
+// LoadPolicy 实现 persist.Adapter
+func (a *Adapter) LoadPolicy(m model.Model) error {
+	rows, err := a.db.QueryContext(context.Background(), "SELECT "+ruleColumns+" FROM casbin_rule ORDER BY id")
+	if err != nil {
+		return err
+	}
+	rules, err := scanRules(rows)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err := persist.LoadPolicyArray(rule, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SavePolicy 实现 persist.Adapter，使用内存中的策略覆盖表中的全部策略
+func (a *Adapter) SavePolicy(m model.Model) error {
+	return a.db.InTx(context.Background(), func(ctx context.Context) error {
+		if _, err := a.db.ExecContext(ctx, "DELETE FROM casbin_rule"); err != nil {
+			return err
+		}
+		for _, sec := range []string{"p", "g"} {
+			for ptype, ast := range m[sec] {
+				for _, rule := range ast.Policy {
+					if err := a.insert(ctx, ptype, rule); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// AddPolicy 实现 persist.Adapter
+func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return a.insert(context.Background(), ptype, rule)
+}
+
+// RemovePolicy 实现 persist.Adapter
+func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.remove(context.Background(), ptype, rule)
+}
+
+// RemoveFilteredPolicy 实现 persist.Adapter，从第 fieldIndex 个字段开始匹配，空值表示不限制
+func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	where, args, err := filter(ptype, fieldIndex, fieldValues)
+	if err != nil {
+		return err
+	}
+	_, err = a.db.ExecContext(context.Background(), "DELETE FROM casbin_rule"+where, args...)
+	return err
+}
+
+// UpdatePolicy 实现 persist.UpdatableAdapter
+func (a *Adapter) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return a.UpdatePolicies(sec, ptype, [][]string{oldRule}, [][]string{newRule})
+}
+
+// UpdatePolicies 实现 persist.UpdatableAdapter
+func (a *Adapter) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return a.db.InTx(context.Background(), func(ctx context.Context) error {
+		for i := range oldRules {
+			if err := a.remove(ctx, ptype, oldRules[i]); err != nil {
+				return err
+			}
+			if err := a.insert(ctx, ptype, newRules[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateFilteredPolicies 实现 persist.UpdatableAdapter，删除匹配的策略后写入新策略，返回被删除的策略
+func (a *Adapter) UpdateFilteredPolicies(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	where, args, err := filter(ptype, fieldIndex, fieldValues)
+	if err != nil {
+		return nil, err
+	}
+	var oldRules [][]string
+	err = a.db.InTx(context.Background(), func(ctx context.Context) error {
+		rows, err := a.db.QueryContext(ctx, "SELECT "+ruleColumns+" FROM casbin_rule"+where+" ORDER BY id", args...)
+		if err != nil {
+			return err
+		}
+		oldRules, err = scanRules(rows)
+		if err != nil {
+			return err
+		}
+		for i := range oldRules {
+			oldRules[i] = oldRules[i][1:]
+		}
+		if _, err := a.db.ExecContext(ctx, "DELETE FROM casbin_rule"+where, args...); err != nil {
+			return err
+		}
+		for _, rule := range newRules {
+			if err := a.insert(ctx, ptype, rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return oldRules, err
+}
+
+func (a *Adapter) insert(ctx context.Context, ptype string, rule []string) error {
+	args, err := ruleArgs(ptype, rule)
+	if err != nil {
+		return err
+	}
+	_, err = a.db.ExecContext(ctx, "INSERT INTO casbin_rule ("+ruleColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)", args...)
+	return err
+}
+
+func (a *Adapter) remove(ctx context.Context, ptype string, rule []string) error {
+	args, err := ruleArgs(ptype, rule)
+	if err != nil {
+		return err
+	}
+	_, err = a.db.ExecContext(ctx,
+		"DELETE FROM casbin_rule WHERE ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ? AND v5 = ?", args...)
+	return err
+}
+
+func ruleArgs(ptype string, rule []string) ([]any, error) {
+	if len(rule) > maxValues {
+		return nil, errors.New("authz: policy has too many fields")
+	}
+	args := make([]any, maxValues+1)
+	args[0] = ptype
+	for i := 0; i < maxValues; i++ {
+		args[i+1] = ""
+		if i < len(rule) {
+			args[i+1] = rule[i]
+		}
+	}
+	return args, nil
+}
+
+// scanRules 读取 ptype, v0 - v5，去掉末尾未使用的字段
+func scanRules(rows *sql.Rows) ([][]string, error) {
+	defer rows.Close()
+	var rules [][]string
+	for rows.Next() {
+		r := make([]string, maxValues+1)
+		if err := rows.Scan(&r[0], &r[1], &r[2], &r[3], &r[4], &r[5], &r[6]); err != nil {
+			return nil, err
+		}
+		n := len(r)
+		for n > 1 && r[n-1] == "" {
+			n--
+		}
+		rules = append(rules, r[:n])
+	}
+	return rules, rows.Err()
+}
+
+// filter 生成 RemoveFilteredPolicy 的 WHERE 子句
+func filter(ptype string, fieldIndex int, fieldValues []string) (string, []any, error) {
+	if fieldIndex < 0 || fieldIndex+len(fieldValues) > maxValues {
+		return "", nil, errors.New("authz: invalid policy filter")
+	}
+	where := []string{"ptype = ?"}
+	args := []any{ptype}
+	for i, v := range fieldValues {
+		if v != "" {
+			where = append(where, fmt.Sprintf("v%d = ?", fieldIndex+i))
+			args = append(args, v)
+		}
+	}
+	return " WHERE " + strings.Join(where, " AND "), args, nil
+}
@@ -0,0 +1,354 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"slices"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// DefaultModel 内置的 RBAC 模型
+// obj 为操作名或 HTTP 路径，支持 keyMatch2 通配，如 /v1.helloworld.Greeter/*、/api/v1/greeter/user/:id
+// act 为 HTTP 方法，gRPC 请求为 POST，"*" 表示任意方法
+const DefaultModel = `
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && (p.act == "*" || r.act == p.act)
+`
+
+const (
+	// PolicyType 权限规则：subject 对 object 执行 action
+	PolicyType = "p"
+	// GroupingType 角色绑定：subject 属于角色 object
+	GroupingType = "g"
+
+	defaultAdminRole = "admin"
+)
+
+// ErrInvalidRule 策略类型或字段不合法
+var ErrInvalidRule = errors.New("authz: invalid rule")
+
+// Rule 策略，Ptype 为 p 时 Action 必填，为 g 时忽略 Action
+type Rule struct {
+	Ptype   string
+	Subject string
+	Object  string
+	Action  string
+}
+
+// Option 选项
+type Option func(*options)
+
+type options struct {
+	model     string
+	modelFile string
+	adminRole string
+	autoLoad  time.Duration
+	watcher   persist.Watcher
+	logger    log.Logger
+	initRules []Rule
+	admins    []string
+}
+
+// WithModel 使用文本形式的模型，默认 DefaultModel
+func WithModel(text string) Option {
+	return func(o *options) {
+		o.model = text
+	}
+}
+
+// WithModelFile 从文件加载模型
+func WithModelFile(path string) Option {
+	return func(o *options) {
+		o.modelFile = path
+	}
+}
+
+// WithAdminRole 设置拥有全部权限的角色，默认 "admin"
+func WithAdminRole(role string) Option {
+	return func(o *options) {
+		if role != "" {
+			o.adminRole = role
+		}
+	}
+}
+
+// WithAutoLoad 定时从存储重新加载策略
+func WithAutoLoad(interval time.Duration) Option {
+	return func(o *options) {
+		o.autoLoad = interval
+	}
+}
+
+// WithWatcher 策略变更时通知其他实例重新加载
+func WithWatcher(w persist.Watcher) Option {
+	return func(o *options) {
+		o.watcher = w
+	}
+}
+
+// WithInitRules 策略为空（如首次启动）时写入的初始策略
+func WithInitRules(rules ...Rule) Option {
+	return func(o *options) {
+		o.initRules = append(o.initRules, rules...)
+	}
+}
+
+// WithInitAdmins 策略为空时将 subjects 绑定到管理员角色，用于创建第一个管理员
+func WithInitAdmins(subjects ...string) Option {
+	return func(o *options) {
+		o.admins = append(o.admins, subjects...)
+	}
+}
+
+// WithLogger 设置日志
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Authorizer 基于 casbin 的鉴权器，并发安全
+type Authorizer struct {
+	e         *casbin.SyncedEnforcer
+	persisted bool
+	adminRole string
+	watcher   persist.Watcher
+	log       *log.Helper
+}
+
+// New 创建鉴权器并加载策略，adapter 为 nil 时策略仅保存在内存中
+func New(adapter persist.Adapter, opts ...Option) (*Authorizer, error) {
+	o := options{model: DefaultModel, adminRole: defaultAdminRole, logger: log.GetLogger()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var (
+		m   model.Model
+		err error
+	)
+	if o.modelFile != "" {
+		m, err = model.NewModelFromFile(o.modelFile)
+	} else {
+		m, err = model.NewModelFromString(o.model)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("authz: load model: %w", err)
+	}
+	params := []interface{}{m}
+	if adapter != nil {
+		params = append(params, adapter)
+	}
+	e, err := casbin.NewSyncedEnforcer(params...)
+	if err != nil {
+		return nil, fmt.Errorf("authz: load policy: %w", err)
+	}
+	e.EnableLog(false)
+
+	a := &Authorizer{e: e, persisted: adapter != nil, adminRole: o.adminRole, watcher: o.watcher, log: log.NewHelper(o.logger)}
+	if o.watcher != nil {
+		if err := e.SetWatcher(o.watcher); err != nil {
+			return nil, fmt.Errorf("authz: set watcher: %w", err)
+		}
+	}
+	rules := o.initRules
+	for _, sub := range o.admins {
+		rules = append(rules, Rule{Ptype: GroupingType, Subject: sub, Object: a.adminRole})
+	}
+	if err := a.init(rules); err != nil {
+		return nil, fmt.Errorf("authz: init policies: %w", err)
+	}
+	if o.autoLoad > 0 && adapter != nil {
+		e.StartAutoLoadPolicy(o.autoLoad)
+	}
+	return a, nil
+}
+
+// init 策略为空时写入 rules；多个实例同时初始化时以先写入存储的为准
+func (a *Authorizer) init(rules []Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	for _, r := range rules {
+		if r.Subject == "" || r.Object == "" || r.Ptype == PolicyType && r.Action == "" {
+			return fmt.Errorf("%w: %+v", ErrInvalidRule, r)
+		}
+	}
+	current, err := a.Rules()
+	if err != nil || len(current) > 0 {
+		return err
+	}
+	for _, r := range rules {
+		if _, err := a.Add(r); err != nil {
+			if !a.persisted || a.e.LoadPolicy() != nil {
+				return err
+			}
+			if current, _ = a.Rules(); len(current) == 0 {
+				return err
+			}
+			a.log.Warnf("authz: policies were initialized by another instance: %v", err)
+			return nil
+		}
+	}
+	a.log.Infof("authz: policies are empty, added %d initial policies", len(rules))
+	return nil
+}
+
+// Enforce 判断任一 subject 能否对 obj 执行 act，任一 subject 为管理员时直接通过
+func (a *Authorizer) Enforce(subjects []string, obj, act string) (bool, error) {
+	if admin, err := a.isAdmin(subjects); err != nil || admin {
+		return admin, err
+	}
+	for _, sub := range subjects {
+		if sub == "" {
+			continue
+		}
+		ok, err := a.e.Enforce(sub, obj, act)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// IsAdmin 判断当前请求的用户是否为管理员，subject 与 token 中的角色均参与判断
+func (a *Authorizer) IsAdmin(ctx context.Context) bool {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	admin, err := a.isAdmin(Subjects(claims))
+	if err != nil {
+		a.log.WithContext(ctx).Errorf("authz: check admin role of %s: %v", claims.Subject, err)
+	}
+	return admin
+}
+
+// Subjects 返回参与鉴权的 subject：token 的 subject 与其角色
+func Subjects(claims *auth.Claims) []string {
+	return append([]string{claims.Subject}, claims.Roles...)
+}
+
+// isAdmin 判断任一 subject 是否为管理员角色，或通过 g 规则（含多级继承）属于管理员角色
+func (a *Authorizer) isAdmin(subjects []string) (bool, error) {
+	for _, sub := range subjects {
+		if sub == "" {
+			continue
+		}
+		if sub == a.adminRole {
+			return true, nil
+		}
+		roles, err := a.e.GetImplicitRolesForUser(sub)
+		if err != nil {
+			return false, err
+		}
+		if slices.Contains(roles, a.adminRole) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Rules 返回全部策略，权限规则在前
+func (a *Authorizer) Rules() ([]Rule, error) {
+	policies, err := a.e.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+	groupings, err := a.e.GetGroupingPolicy()
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, 0, len(policies)+len(groupings))
+	for _, p := range policies {
+		rules = append(rules, Rule{Ptype: PolicyType, Subject: field(p, 0), Object: field(p, 1), Action: field(p, 2)})
+	}
+	for _, g := range groupings {
+		rules = append(rules, Rule{Ptype: GroupingType, Subject: field(g, 0), Object: field(g, 1)})
+	}
+	return rules, nil
+}
+
+// Add 添加策略，已存在时返回 false
+func (a *Authorizer) Add(r Rule) (bool, error) {
+	switch r.Ptype {
+	case PolicyType:
+		return a.e.AddPolicy(r.Subject, r.Object, r.Action)
+	case GroupingType:
+		return a.e.AddGroupingPolicy(r.Subject, r.Object)
+	}
+	return false, ErrInvalidRule
+}
+
+// Remove 删除策略，不存在时返回 false
+func (a *Authorizer) Remove(r Rule) (bool, error) {
+	switch r.Ptype {
+	case PolicyType:
+		return a.e.RemovePolicy(r.Subject, r.Object, r.Action)
+	case GroupingType:
+		return a.e.RemoveGroupingPolicy(r.Subject, r.Object)
+	}
+	return false, ErrInvalidRule
+}
+
+// Update 替换策略，两者类型必须相同，旧策略不存在时返回 false
+func (a *Authorizer) Update(old, r Rule) (bool, error) {
+	if old.Ptype != r.Ptype {
+		return false, ErrInvalidRule
+	}
+	switch r.Ptype {
+	case PolicyType:
+		return a.e.UpdatePolicy([]string{old.Subject, old.Object, old.Action}, []string{r.Subject, r.Object, r.Action})
+	case GroupingType:
+		return a.e.UpdateGroupingPolicy([]string{old.Subject, old.Object}, []string{r.Subject, r.Object})
+	}
+	return false, ErrInvalidRule
+}
+
+// Reload 从存储重新加载策略，并通知其他实例，策略仅保存在内存中时不做任何操作
+func (a *Authorizer) Reload() error {
+	if !a.persisted {
+		return nil
+	}
+	if err := a.e.LoadPolicy(); err != nil {
+		return err
+	}
+	if a.watcher != nil {
+		return a.watcher.Update()
+	}
+	return nil
+}
+
+// Close 停止定时加载与变更通知
+func (a *Authorizer) Close() {
+	a.e.StopAutoLoadPolicy()
+	if a.watcher != nil {
+		a.watcher.Close()
+	}
+}
+
+func field(rule []string, i int) string {
+	if i < len(rule) {
+		return rule[i]
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package authz
+
+import (
+	"context"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// ReasonPermissionDenied 鉴权失败的错误原因，响应码为 response.CasbinFailed
+const ReasonPermissionDenied = "PERMISSION_DENIED"
+
+// ErrPermissionDenied 当前用户无权调用该接口
+var ErrPermissionDenied = errors.Forbidden(ReasonPermissionDenied, "permission denied")
+
+func init() {
+	response.RegisterReason(ReasonPermissionDenied, response.CasbinFailed)
+}
+
+// Server 鉴权中间件，需放在认证中间件之后
+// 使用 token 中的 subject 与角色依次匹配操作名与 HTTP 路径，未认证的请求（认证白名单）直接放行
+// allowList 中的操作或 HTTP 路径只要求已认证，用于只访问本人数据的接口，以 * 结尾表示前缀匹配
+func Server(a *Authorizer, allowList ...string) middleware.Middleware {
+	allow := auth.NewAllowList(allowList...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			claims, ok := auth.FromContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, ErrPermissionDenied
+			}
+			if allow.Match(ctx, tr.Operation()) {
+				return handler(ctx, req)
+			}
+			subjects := Subjects(claims)
+			objects := []string{tr.Operation()}
+			act := http.MethodPost
+			if r, ok := khttp.RequestFromServerContext(ctx); ok {
+				objects = append(objects, r.URL.Path)
+				act = r.Method
+			}
+			for _, obj := range objects {
+				allowed, err := a.Enforce(subjects, obj, act)
+				if err != nil {
+					a.log.WithContext(ctx).Errorf("authz: enforce %s %s: %v", act, obj, err)
+					return nil, ErrPermissionDenied.WithCause(err)
+				}
+				if allowed {
+					return handler(ctx, req)
+				}
+			}
+			return nil, ErrPermissionDenied
+		}
+	}
+}
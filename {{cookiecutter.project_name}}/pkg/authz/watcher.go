@@ -0,0 +1,73 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"{{cookiecutter.project_name}}/pkg/notify"
+
+	"github.com/casbin/casbin/v2/persist"
+)
+
+// Notifier 分发策略变更事件，notify.Notifier 实现了该接口
+type Notifier interface {
+	Publish(ctx context.Context, e notify.Event) error
+	Subscribe(topic string, fn func(notify.Event)) (cancel func())
+}
+
+// Watcher 通过 Notifier 通知其他实例重新加载策略，忽略本实例发出的通知
+type Watcher struct {
+	n        Notifier
+	topic    string
+	instance string
+	cancel   func()
+
+	mu       sync.RWMutex
+	callback func(string)
+}
+
+var _ persist.Watcher = (*Watcher)(nil)
+
+// NewWatcher 订阅 topic，事件的 Key 为发出通知的实例
+func NewWatcher(n Notifier, topic string) *Watcher {
+	w := &Watcher{
+		n:        n,
+		topic:    topic,
+		instance: fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano()),
+	}
+	w.cancel = n.Subscribe(topic, w.receive)
+	return w
+}
+
+// SetUpdateCallback 实现 persist.Watcher
+func (w *Watcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Update 实现 persist.Watcher，策略变更后由 enforcer 调用
+func (w *Watcher) Update() error {
+	return w.n.Publish(context.Background(), notify.Event{Topic: w.topic, Key: w.instance, Action: notify.ActionUpdate})
+}
+
+// Close 实现 persist.Watcher，只取消订阅，Notifier 由创建者关闭
+func (w *Watcher) Close() {
+	w.cancel()
+}
+
+func (w *Watcher) receive(e notify.Event) {
+	if e.Key == w.instance {
+		return
+	}
+	w.mu.RLock()
+	callback := w.callback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(e.Key)
+	}
+}
@@ -0,0 +1,89 @@
+package breaker
+
+import (
+	"context"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/metric"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// ReasonBreakerOpen 熔断器拒绝了请求
+const ReasonBreakerOpen = "CIRCUIT_BREAKER_OPEN"
+
+// ErrBreakerOpen 下游服务失败率过高，请求在本地被拒绝
+var ErrBreakerOpen = errors.ServiceUnavailable(ReasonBreakerOpen, "request rejected by circuit breaker")
+
+// Options 将配置转换为 sre 熔断器选项，c 中未设置的字段使用 fallback
+func Options(c, fallback *conf.Client_Breaker) ([]sre.Option, error) {
+	var opts []sre.Option
+	if v := c.GetSuccess(); v > 0 {
+		opts = append(opts, sre.WithSuccess(v))
+	} else if v := fallback.GetSuccess(); v > 0 {
+		opts = append(opts, sre.WithSuccess(v))
+	}
+	if v := c.GetRequest(); v > 0 {
+		opts = append(opts, sre.WithRequest(v))
+	} else if v := fallback.GetRequest(); v > 0 {
+		opts = append(opts, sre.WithRequest(v))
+	}
+	if v := c.GetBucket(); v > 0 {
+		opts = append(opts, sre.WithBucket(int(v)))
+	} else if v := fallback.GetBucket(); v > 0 {
+		opts = append(opts, sre.WithBucket(int(v)))
+	}
+	window := c.GetWindow()
+	if window == "" {
+		window = fallback.GetWindow()
+	}
+	if window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("breaker: invalid window %q", window)
+		}
+		opts = append(opts, sre.WithWindow(d))
+	}
+	return opts, nil
+}
+
+// Client 熔断中间件，每个操作使用独立的熔断器
+// 下游返回 5xx、503 或 504 时计为失败，service 用于监控标签
+func Client(service string, opts ...sre.Option) middleware.Middleware {
+	var breakers sync.Map
+	get := func(operation string) circuitbreaker.CircuitBreaker {
+		if b, ok := breakers.Load(operation); ok {
+			return b.(circuitbreaker.CircuitBreaker)
+		}
+		b, _ := breakers.LoadOrStore(operation, sre.NewBreaker(opts...))
+		return b.(circuitbreaker.CircuitBreaker)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			operation := ""
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				operation = tr.Operation()
+			}
+			b := get(operation)
+			if err := b.Allow(); err != nil {
+				// 本地拒绝也计为失败，使拒绝概率随失败持续上升
+				b.MarkFailed()
+				metric.BreakerRejectedCount.With(service, operation).Inc()
+				return nil, ErrBreakerOpen
+			}
+			reply, err := handler(ctx, req)
+			if err != nil && (errors.IsInternalServer(err) || errors.IsServiceUnavailable(err) || errors.IsGatewayTimeout(err)) {
+				b.MarkFailed()
+			} else {
+				b.MarkSuccess()
+			}
+			return reply, err
+		}
+	}
+}
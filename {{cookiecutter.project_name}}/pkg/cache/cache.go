@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"{{cookiecutter.project_name}}/pkg/metric"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultTTL         = 10 * time.Minute
+	defaultNegativeTTL = time.Minute
+	defaultLoadTimeout = 5 * time.Second
+	defaultDeleteDelay = 500 * time.Millisecond
+	// negativeValue 负缓存占位值，表示数据不存在
+	negativeValue = "\x00nil"
+)
+
+// Cache 基于 Redis 的旁路缓存
+// rdb 为 nil 时退化为仅合并并发加载的直通模式，便于未配置 Redis 的环境
+type Cache[T any] struct {
+	rdb   redis.UniversalClient
+	group singleflight.Group
+	opts  options
+	// deletes 本进程内 Delete 的次数，加载期间发生过删除时不回填
+	deletes atomic.Uint64
+}
+
+// Option 缓存选项
+type Option func(*options)
+
+type options struct {
+	name        string
+	ttl         time.Duration
+	negativeTTL time.Duration
+	jitter      float64
+	notFound    error
+	isNotFound  func(error) bool
+	loadTimeout time.Duration
+	deleteDelay time.Duration
+}
+
+// WithTTL 设置缓存有效期
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithJitter 设置有效期的随机浮动比例，避免大量 key 同时过期，默认 0.1
+func WithJitter(jitter float64) Option {
+	return func(o *options) {
+		o.jitter = jitter
+	}
+}
+
+// WithLoadTimeout 设置回源加载的超时时间，默认 5s
+// 加载由并发请求共享，不受单个请求取消的影响
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.loadTimeout = timeout
+	}
+}
+
+// WithDeleteDelay 设置延迟双删的间隔，默认 500ms，为 0 时不做第二次删除
+// 第二次删除用于清理删除前已开始的加载回填的旧值
+func WithDeleteDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.deleteDelay = delay
+	}
+}
+
+// WithNegative 开启负缓存
+// is 判断加载结果是否为数据不存在，命中负缓存时返回 notFound
+func WithNegative(notFound error, is func(error) bool, ttl time.Duration) Option {
+	return func(o *options) {
+		o.notFound = notFound
+		o.isNotFound = is
+		if ttl > 0 {
+			o.negativeTTL = ttl
+		}
+	}
+}
+
+// New 创建旁路缓存，name 作为 key 前缀及指标标签
+func New[T any](rdb redis.UniversalClient, name string, opts ...Option) *Cache[T] {
+	o := options{
+		name:        name,
+		ttl:         defaultTTL,
+		negativeTTL: defaultNegativeTTL,
+		jitter:      0.1,
+		loadTimeout: defaultLoadTimeout,
+		deleteDelay: defaultDeleteDelay,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Cache[T]{rdb: rdb, opts: o}
+}
+
+// Get 读取缓存，未命中时通过 load 加载并回填
+// 同一 key 的并发加载只会执行一次，每个调用方仍可通过自己的 ctx 提前返回
+func (c *Cache[T]) Get(ctx context.Context, key string, load func(context.Context) (T, error)) (T, error) {
+	var zero T
+	key = c.key(key)
+	if c.rdb != nil {
+		data, err := c.rdb.Get(ctx, key).Result()
+		switch {
+		case err == nil && data == negativeValue:
+			c.count("negative_hit")
+			return zero, c.opts.notFound
+		case err == nil:
+			var v T
+			if err := json.Unmarshal([]byte(data), &v); err == nil {
+				c.count("hit")
+				return v, nil
+			}
+			c.count("error")
+		case errors.Is(err, redis.Nil):
+			c.count("miss")
+		default:
+			// Redis 不可用时直接回源
+			c.count("error")
+		}
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.opts.loadTimeout)
+		defer cancel()
+		deletes := c.deletes.Load()
+		v, err := load(ctx)
+		if c.rdb == nil || c.deletes.Load() != deletes {
+			return v, err
+		}
+		switch {
+		case err == nil:
+			if data, e := json.Marshal(v); e == nil {
+				c.rdb.Set(ctx, key, data, c.expiration(c.opts.ttl))
+			}
+		case c.opts.isNotFound != nil && c.opts.isNotFound(err):
+			c.rdb.Set(ctx, key, negativeValue, c.expiration(c.opts.negativeTTL))
+		}
+		return v, err
+	})
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			return zero, r.Err
+		}
+		return r.Val.(T), nil
+	}
+}
+
+// Delete 删除缓存，数据更新后调用
+// 删除后间隔 deleteDelay 再删除一次，避免并发加载把旧值写回
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) error {
+	if c.rdb == nil || len(keys) == 0 {
+		return nil
+	}
+	c.deletes.Add(1)
+	full := make([]string, 0, len(keys))
+	for _, k := range keys {
+		full = append(full, c.key(k))
+		// 删除前开始的加载可能读到旧值，之后的请求不再复用它
+		c.group.Forget(c.key(k))
+	}
+	if c.opts.deleteDelay > 0 {
+		ctx := context.WithoutCancel(ctx)
+		time.AfterFunc(c.opts.deleteDelay, func() {
+			if err := c.rdb.Del(ctx, full...).Err(); err != nil {
+				c.count("error")
+			}
+		})
+	}
+	return c.rdb.Del(ctx, full...).Err()
+}
+
+func (c *Cache[T]) key(k string) string {
+	return c.opts.name + ":" + k
+}
+
+func (c *Cache[T]) expiration(ttl time.Duration) time.Duration {
+	if c.opts.jitter <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Float64()*c.opts.jitter*float64(ttl))
+}
+
+func (c *Cache[T]) count(result string) {
+	metric.CacheCount.With(c.opts.name, result).Inc()
+}
@@ -0,0 +1,305 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var errNotFound = errors.New("not found")
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return mr, rdb
+}
+
+// loader 返回 v 并记录调用次数
+func loader(calls *atomic.Int32, v user, err error) func(context.Context) (user, error) {
+	return func(context.Context) (user, error) {
+		calls.Add(1)
+		return v, err
+	}
+}
+
+func TestGetLoadsOnceWithTTL(t *testing.T) {
+	ctx := context.Background()
+	mr, rdb := newRedis(t)
+	c := New[user](rdb, "user", WithTTL(time.Minute), WithJitter(0))
+
+	var calls atomic.Int32
+	want := user{ID: 1, Name: "kratos"}
+	for i := 0; i < 3; i++ {
+		got, err := c.Get(ctx, "1", loader(&calls, want, nil))
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if got != want {
+			t.Fatalf("Get = %+v, want %+v", got, want)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("load called %d times, want 1", n)
+	}
+	if ttl := mr.TTL("user:1"); ttl != time.Minute {
+		t.Fatalf("TTL = %v, want %v", ttl, time.Minute)
+	}
+
+	mr.FastForward(time.Minute)
+	if _, err := c.Get(ctx, "1", loader(&calls, want, nil)); err != nil {
+		t.Fatalf("Get after expiration: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("load called %d times after expiration, want 2", n)
+	}
+}
+
+func TestGetJitter(t *testing.T) {
+	mr, rdb := newRedis(t)
+	c := New[user](rdb, "user", WithTTL(time.Minute), WithJitter(0.5))
+
+	var calls atomic.Int32
+	if _, err := c.Get(context.Background(), "1", loader(&calls, user{ID: 1}, nil)); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if ttl := mr.TTL("user:1"); ttl < time.Minute || ttl > 90*time.Second {
+		t.Fatalf("TTL = %v, want between 1m and 1m30s", ttl)
+	}
+}
+
+func TestGetCollapsesConcurrentLoads(t *testing.T) {
+	ctx := context.Background()
+	_, rdb := newRedis(t)
+	c := New[user](rdb, "user")
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	load := func(context.Context) (user, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return user{ID: 1}, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Get(ctx, "1", load); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	<-started
+	// 等待其余请求未命中缓存后进入 singleflight
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("load called %d times, want 1", got)
+	}
+}
+
+func TestGetDetachesLoadFromCaller(t *testing.T) {
+	_, rdb := newRedis(t)
+	c := New[user](rdb, "user")
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loadErr := make(chan error, 1)
+	load := func(ctx context.Context) (user, error) {
+		close(started)
+		<-release
+		loadErr <- ctx.Err()
+		return user{ID: 1}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := c.Get(ctx, "1", load)
+		first <- err
+	}()
+	<-started
+	second := make(chan error, 1)
+	go func() {
+		_, err := c.Get(context.Background(), "1", load)
+		second <- err
+	}()
+
+	// 第一个调用方取消后立即返回，共享的加载继续执行
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled Get error = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	if err := <-loadErr; err != nil {
+		t.Fatalf("load ctx error = %v, want nil", err)
+	}
+	if err := <-second; err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+}
+
+func TestDeleteDuringLoad(t *testing.T) {
+	ctx := context.Background()
+	mr, rdb := newRedis(t)
+	// a 与 b 模拟共享 Redis 的两个实例
+	a := New[user](rdb, "user", WithDeleteDelay(50*time.Millisecond))
+	b := New[user](rdb, "user", WithDeleteDelay(50*time.Millisecond))
+
+	stale := func(c *Cache[user], del func()) {
+		t.Helper()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			_, err := c.Get(ctx, "1", func(context.Context) (user, error) {
+				close(started)
+				<-release
+				return user{ID: 1, Name: "old"}, nil
+			})
+			done <- err
+		}()
+		<-started
+		del()
+		close(release)
+		if err := <-done; err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+	}
+
+	// 同一实例内删除后完成的加载不回填
+	stale(a, func() {
+		if err := a.Delete(ctx, "1"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	})
+	if mr.Exists("user:1") {
+		t.Fatal("a load started before Delete should not be cached")
+	}
+
+	// 其他实例回填的旧值由延迟的第二次删除清理
+	stale(a, func() {
+		if err := b.Delete(ctx, "1"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	})
+	if !mr.Exists("user:1") {
+		t.Fatal("the stale value should be cached until the delayed delete")
+	}
+	deadline := time.Now().Add(time.Second)
+	for mr.Exists("user:1") {
+		if time.Now().After(deadline) {
+			t.Fatal("the delayed delete did not remove the stale value")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGetNegative(t *testing.T) {
+	ctx := context.Background()
+	mr, rdb := newRedis(t)
+	c := New[user](rdb, "user", WithJitter(0), WithNegative(errNotFound, func(err error) bool {
+		return errors.Is(err, errNotFound)
+	}, 30*time.Second))
+
+	var calls atomic.Int32
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(ctx, "404", loader(&calls, user{}, errNotFound)); !errors.Is(err, errNotFound) {
+			t.Fatalf("Get error = %v, want %v", err, errNotFound)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("load called %d times, want 1", n)
+	}
+	if v, _ := mr.Get("user:404"); v != negativeValue {
+		t.Fatalf("cached value = %q, want the negative placeholder", v)
+	}
+	if ttl := mr.TTL("user:404"); ttl != 30*time.Second {
+		t.Fatalf("TTL = %v, want 30s", ttl)
+	}
+
+	// 其他错误不缓存
+	boom := errors.New("boom")
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(ctx, "500", loader(&calls, user{}, boom)); !errors.Is(err, boom) {
+			t.Fatalf("Get error = %v, want %v", err, boom)
+		}
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("load called %d times, want 3", n)
+	}
+	if mr.Exists("user:500") {
+		t.Fatal("failed load should not be cached")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	mr, rdb := newRedis(t)
+	c := New[user](rdb, "user")
+
+	var calls atomic.Int32
+	if _, err := c.Get(ctx, "1", loader(&calls, user{ID: 1, Name: "old"}, nil)); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if err := c.Delete(ctx, "1", "2"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if mr.Exists("user:1") {
+		t.Fatal("key should be deleted")
+	}
+	got, err := c.Get(ctx, "1", loader(&calls, user{ID: 1, Name: "new"}, nil))
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Name != "new" || calls.Load() != 2 {
+		t.Fatalf("Get after Delete = %+v with %d loads, want the reloaded value", got, calls.Load())
+	}
+}
+
+func TestNilClient(t *testing.T) {
+	ctx := context.Background()
+	c := New[user](nil, "user")
+
+	var calls atomic.Int32
+	for i := 0; i < 2; i++ {
+		got, err := c.Get(ctx, "1", loader(&calls, user{ID: 1}, nil))
+		if err != nil || got.ID != 1 {
+			t.Fatalf("Get = %+v, %v", got, err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("load called %d times, want 2 without redis", n)
+	}
+	boom := errors.New("boom")
+	if _, err := c.Get(ctx, "1", loader(&calls, user{}, boom)); !errors.Is(err, boom) {
+		t.Fatalf("Get error = %v, want %v", err, boom)
+	}
+	if err := c.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+}
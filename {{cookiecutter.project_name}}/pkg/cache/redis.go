@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultPingTimeout = 5 * time.Second
+
+// NewRedis 根据 conf.Data.Redis 创建 Redis 客户端，并在返回前完成 ping
+func NewRedis(c *conf.Data_Redis) (*redis.Client, error) {
+	opts := &redis.Options{
+		Network:  c.GetNetwork(),
+		Addr:     c.GetAddr(),
+		Username: c.GetUsername(),
+		Password: c.GetPassword(),
+		DB:       int(c.GetDb()),
+		PoolSize: int(c.GetPoolSize()),
+	}
+	var err error
+	if opts.DialTimeout, err = parseDuration(c.GetDialTimeout()); err != nil {
+		return nil, fmt.Errorf("invalid redis dial_timeout: %w", err)
+	}
+	if opts.ReadTimeout, err = parseDuration(c.GetReadTimeout()); err != nil {
+		return nil, fmt.Errorf("invalid redis read_timeout: %w", err)
+	}
+	if opts.WriteTimeout, err = parseDuration(c.GetWriteTimeout()); err != nil {
+		return nil, fmt.Errorf("invalid redis write_timeout: %w", err)
+	}
+
+	rdb := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), defaultPingTimeout)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		_ = rdb.Close()
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return rdb, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
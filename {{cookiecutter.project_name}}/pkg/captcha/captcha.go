@@ -0,0 +1,150 @@
+package captcha
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mojocn/base64Captcha"
+)
+
+const (
+	TypeDigit  = "digit"  // 数字
+	TypeString = "string" // 字母与数字，校验时不区分大小写
+	TypeMath   = "math"   // 算术题，答案为计算结果
+
+	defaultWidth  = 240
+	defaultHeight = 80
+	defaultLength = 4
+	defaultExpire = 5 * time.Minute
+)
+
+// ErrUnknownType 不支持的验证码类型
+var ErrUnknownType = errors.New("captcha: unknown type")
+
+// Captcha 生成的验证码
+type Captcha struct {
+	ID string
+	// Image data:image/png;base64,... 形式的图片
+	Image  string
+	Expire time.Duration
+}
+
+// Option 选项
+type Option func(*options)
+
+type options struct {
+	typ           string
+	width, height int
+	length        int
+	expire        time.Duration
+}
+
+// WithType 设置验证码类型，默认 TypeDigit
+func WithType(typ string) Option {
+	return func(o *options) {
+		if typ != "" {
+			o.typ = typ
+		}
+	}
+}
+
+// WithSize 设置图片宽高，默认 240x80
+func WithSize(width, height int) Option {
+	return func(o *options) {
+		if width > 0 && height > 0 {
+			o.width, o.height = width, height
+		}
+	}
+}
+
+// WithLength 设置字符数，TypeMath 忽略，默认 4
+func WithLength(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.length = n
+		}
+	}
+}
+
+// WithExpire 设置有效期，默认 5 分钟
+func WithExpire(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.expire = d
+		}
+	}
+}
+
+// Generator 生成验证码并保存答案，每个验证码只能校验一次
+type Generator struct {
+	driver base64Captcha.Driver
+	store  Store
+	expire time.Duration
+	fold   bool
+}
+
+// New 创建验证码生成器
+func New(store Store, opts ...Option) (*Generator, error) {
+	o := options{typ: TypeDigit, width: defaultWidth, height: defaultHeight, length: defaultLength, expire: defaultExpire}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	g := &Generator{store: store, expire: o.expire}
+	lines := base64Captcha.OptionShowSlimeLine | base64Captcha.OptionShowSineLine
+	switch o.typ {
+	case TypeDigit:
+		g.driver = base64Captcha.NewDriverDigit(o.height, o.width, o.length, 0.7, 80)
+	case TypeString:
+		g.driver = base64Captcha.NewDriverString(o.height, o.width, 0, lines, o.length,
+			base64Captcha.TxtSimpleCharaters, nil, nil, nil)
+		g.fold = true
+	case TypeMath:
+		g.driver = base64Captcha.NewDriverMath(o.height, o.width, 0, lines, nil, nil, nil)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownType, o.typ)
+	}
+	return g, nil
+}
+
+// Generate 生成验证码并保存答案
+func (g *Generator) Generate(ctx context.Context) (*Captcha, error) {
+	_, question, answer := g.driver.GenerateIdQuestionAnswer()
+	item, err := g.driver.DrawCaptcha(question)
+	if err != nil {
+		return nil, fmt.Errorf("captcha: draw: %w", err)
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(b[:])
+	if err := g.store.Set(ctx, id, g.normalize(answer), g.expire); err != nil {
+		return nil, fmt.Errorf("captcha: save: %w", err)
+	}
+	return &Captcha{ID: id, Image: item.EncodeB64string(), Expire: g.expire}, nil
+}
+
+// Verify 校验答案，无论是否正确验证码都会失效；不存在或已过期时返回 false
+func (g *Generator) Verify(ctx context.Context, id, answer string) (bool, error) {
+	if id == "" || answer == "" {
+		return false, nil
+	}
+	expected, ok, err := g.store.Take(ctx, id)
+	if err != nil || !ok {
+		return false, err
+	}
+	return expected == g.normalize(answer), nil
+}
+
+func (g *Generator) normalize(answer string) string {
+	answer = strings.TrimSpace(answer)
+	if g.fold {
+		answer = strings.ToLower(answer)
+	}
+	return answer
+}
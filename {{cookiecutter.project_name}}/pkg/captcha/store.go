@@ -0,0 +1,133 @@
+package captcha
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Store 保存验证码答案
+type Store interface {
+	// Set 保存答案，ttl 后过期
+	Set(ctx context.Context, id, answer string, ttl time.Duration) error
+	// Take 取出并删除答案，不存在或已过期时返回 false
+	Take(ctx context.Context, id string) (string, bool, error)
+}
+
+const (
+	sweepInterval = time.Minute
+	// DefaultMemoryLimit MemoryStore 默认最多保存的验证码数
+	DefaultMemoryLimit = 100000
+)
+
+// MemoryStore 进程内存储，多实例部署时需使用 RedisStore
+// 最多保存 limit 个验证码，超出时淘汰最早生成的，避免频繁请求验证码耗尽内存
+type MemoryStore struct {
+	mu        sync.Mutex
+	limit     int
+	items     map[string]*list.Element
+	order     *list.List // 按生成顺序排列的 *memoryItem
+	lastSweep time.Time
+}
+
+type memoryItem struct {
+	id       string
+	answer   string
+	expireAt time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建进程内存储，limit 不大于 0 时使用 DefaultMemoryLimit
+func NewMemoryStore(limit int) *MemoryStore {
+	if limit <= 0 {
+		limit = DefaultMemoryLimit
+	}
+	return &MemoryStore{limit: limit, items: make(map[string]*list.Element), order: list.New()}
+}
+
+// Set 实现 Store
+func (s *MemoryStore) Set(_ context.Context, id, answer string, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	if e, ok := s.items[id]; ok {
+		s.remove(e)
+	}
+	for len(s.items) >= s.limit {
+		s.remove(s.order.Front())
+	}
+	s.items[id] = s.order.PushBack(&memoryItem{id: id, answer: answer, expireAt: now.Add(ttl)})
+	return nil
+}
+
+// Take 实现 Store
+func (s *MemoryStore) Take(_ context.Context, id string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.items[id]
+	if !ok {
+		return "", false, nil
+	}
+	s.remove(e)
+	item := e.Value.(*memoryItem)
+	if time.Now().After(item.expireAt) {
+		return "", false, nil
+	}
+	return item.answer, true, nil
+}
+
+// sweep 定期清理过期的验证码
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for e := s.order.Front(); e != nil; {
+		next := e.Next()
+		if now.After(e.Value.(*memoryItem).expireAt) {
+			s.remove(e)
+		}
+		e = next
+	}
+}
+
+func (s *MemoryStore) remove(e *list.Element) {
+	s.order.Remove(e)
+	delete(s.items, e.Value.(*memoryItem).id)
+}
+
+// RedisStore 基于 Redis 的存储，多实例共享
+type RedisStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore 创建 Redis 存储，key 为 prefix + id
+func NewRedisStore(rdb redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{rdb: rdb, prefix: prefix}
+}
+
+// Set 实现 Store
+func (s *RedisStore) Set(ctx context.Context, id, answer string, ttl time.Duration) error {
+	return s.rdb.Set(ctx, s.prefix+id, answer, ttl).Err()
+}
+
+// Take 实现 Store，使用 GETDEL 保证只能取出一次
+func (s *RedisStore) Take(ctx context.Context, id string) (string, bool, error) {
+	answer, err := s.rdb.GetDel(ctx, s.prefix+id).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return answer, true, nil
+}
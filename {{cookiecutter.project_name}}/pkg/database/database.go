@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/metric"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	_ "github.com/glebarez/go-sqlite"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	defaultPingTimeout   = 5 * time.Second
+	defaultSlowThreshold = 200 * time.Millisecond
+	statsInterval        = 15 * time.Second
+)
+
+// DB 带连接池、链路追踪、指标与慢查询日志的 SQL 客户端
+type DB struct {
+	*sql.DB
+	dialect Dialect
+	slow    time.Duration
+	tracer  trace.Tracer
+	log     *log.Helper
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// Open 根据 conf.Data.Database 打开数据库连接池，并在返回前完成 ping
+func Open(c *conf.Data_Database, logger log.Logger) (*DB, error) {
+	dialect, err := ParseDialect(c.GetDriver())
+	if err != nil {
+		return nil, err
+	}
+	lifetime, err := parseDuration(c.GetConnMaxLifetime(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database connMaxLifetime: %w", err)
+	}
+	idleTime, err := parseDuration(c.GetConnMaxIdleTime(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database connMaxIdleTime: %w", err)
+	}
+	slow, err := parseDuration(c.GetSlowThreshold(), defaultSlowThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database slowThreshold: %w", err)
+	}
+
+	sqlDB, err := sql.Open(dialect.DriverName(), c.GetSource())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if c.GetMaxOpenConns() > 0 {
+		sqlDB.SetMaxOpenConns(int(c.GetMaxOpenConns()))
+	}
+	if c.GetMaxIdleConns() > 0 {
+		sqlDB.SetMaxIdleConns(int(c.GetMaxIdleConns()))
+	}
+	sqlDB.SetConnMaxLifetime(lifetime)
+	sqlDB.SetConnMaxIdleTime(idleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultPingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	db := &DB{
+		DB:      sqlDB,
+		dialect: dialect,
+		slow:    slow,
+		tracer:  otel.Tracer("database"),
+		log:     log.NewHelper(log.With(logger, "module", "database")),
+		stop:    make(chan struct{}),
+	}
+	go db.reportStats()
+	return db, nil
+}
+
+// Dialect 返回数据库方言
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
+
+// Close 停止指标上报并关闭连接池，可重复调用
+func (db *DB) Close() error {
+	db.once.Do(func() { close(db.stop) })
+	return db.DB.Close()
+}
+
+// ExecContext 执行 SQL，占位符统一使用 "?"，ctx 处于 InTx 中时在事务内执行
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return observe(db, ctx, "exec", query, func(ctx context.Context, query string) (sql.Result, error) {
+		return db.conn(ctx).ExecContext(ctx, query, args...)
+	})
+}
+
+// QueryContext 查询多行，占位符统一使用 "?"
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return observe(db, ctx, "query", query, func(ctx context.Context, query string) (*sql.Rows, error) {
+		return db.conn(ctx).QueryContext(ctx, query, args...)
+	})
+}
+
+// QueryRowContext 查询单行，占位符统一使用 "?"
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	row, _ := observe(db, ctx, "query_row", query, func(ctx context.Context, query string) (*sql.Row, error) {
+		row := db.conn(ctx).QueryRowContext(ctx, query, args...)
+		return row, row.Err()
+	})
+	return row
+}
+
+// observe 改写占位符并记录链路、耗时指标与慢查询
+func observe[T any](db *DB, ctx context.Context, op, query string, fn func(context.Context, string) (T, error)) (T, error) {
+	query = db.dialect.Rebind(query)
+	ctx, span := db.tracer.Start(ctx, "db."+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", db.dialect.String()),
+			attribute.String("db.statement", query),
+		))
+	defer span.End()
+
+	start := time.Now()
+	rv, err := fn(ctx, query)
+	cost := time.Since(start)
+
+	status := "ok"
+	if err != nil && err != sql.ErrNoRows {
+		status = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	metric.DBDurationHistogram.With(db.dialect.String(), op, status).Observe(cost.Seconds())
+	if cost >= db.slow {
+		metric.DBSlowCount.With(db.dialect.String(), op).Inc()
+		db.log.WithContext(ctx).Warnw("msg", "slow query", "cost", cost.String(), "sql", query)
+	}
+	return rv, err
+}
+
+// reportStats 定期上报连接池状态
+func (db *DB) reportStats() {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-db.stop:
+			return
+		case <-ticker.C:
+			s := db.Stats()
+			driver := db.dialect.String()
+			metric.DBConnectionsGauge.With(driver, "open").Set(float64(s.OpenConnections))
+			metric.DBConnectionsGauge.With(driver, "in_use").Set(float64(s.InUse))
+			metric.DBConnectionsGauge.With(driver, "idle").Set(float64(s.Idle))
+			metric.DBConnectionsGauge.With(driver, "wait_count").Set(float64(s.WaitCount))
+		}
+	}
+}
+
+func parseDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect 数据库方言
+type Dialect int
+
+const (
+	MySQL Dialect = iota + 1
+	Postgres
+	SQLite
+)
+
+// ParseDialect 解析 conf.Data.Database.driver
+func ParseDialect(driver string) (Dialect, error) {
+	switch strings.ToLower(driver) {
+	case "mysql":
+		return MySQL, nil
+	case "postgres", "postgresql", "pgx":
+		return Postgres, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	default:
+		return 0, fmt.Errorf("unsupported database driver: %q", driver)
+	}
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case MySQL:
+		return "mysql"
+	case Postgres:
+		return "postgres"
+	case SQLite:
+		return "sqlite"
+	default:
+		return "unknown"
+	}
+}
+
+// DriverName 返回 database/sql 中注册的驱动名
+func (d Dialect) DriverName() string {
+	if d == Postgres {
+		return "pgx"
+	}
+	return d.String()
+}
+
+// Rebind 将 "?" 占位符改写为方言对应的形式，跳过引号内的内容
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var (
+		b     strings.Builder
+		n     int
+		quote byte
+	)
+	b.Grow(len(query) + 8)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Insert 执行 INSERT 并返回自增主键，Postgres 通过 RETURNING 获取
+func (db *DB) Insert(ctx context.Context, query string, args ...any) (int64, error) {
+	if db.dialect == Postgres {
+		var id int64
+		err := db.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
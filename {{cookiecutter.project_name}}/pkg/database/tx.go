@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type txKey struct{}
+
+// txState 上下文中的事务状态，每层嵌套对应一个 txState
+type txState struct {
+	tx          *sql.Tx
+	depth       int
+	parent      *txState
+	afterCommit []func(context.Context)
+	savepoint   string
+}
+
+// conn 执行 SQL 的连接，*sql.DB 与 *sql.Tx 均实现
+type conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn 返回上下文中的事务，不在事务中时返回连接池
+func (db *DB) conn(ctx context.Context) conn {
+	if s, ok := ctx.Value(txKey{}).(*txState); ok {
+		return s.tx
+	}
+	return db.DB
+}
+
+// InTransaction 判断上下文是否处于事务中
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// InTx 在事务中执行 fn，fn 内使用传入的 ctx 执行的 SQL 均加入该事务
+// fn 返回错误或 panic 时回滚；已处于事务中时通过 savepoint 嵌套，仅回滚到本层
+func (db *DB) InTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	parent, _ := ctx.Value(txKey{}).(*txState)
+	s := &txState{parent: parent}
+	if parent == nil {
+		if s.tx, err = db.BeginTx(ctx, nil); err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+	} else {
+		s.tx = parent.tx
+		s.depth = parent.depth + 1
+		s.savepoint = fmt.Sprintf("sp_%d", s.depth)
+		if _, err = db.exec(ctx, s.tx, "SAVEPOINT "+s.savepoint); err != nil {
+			return fmt.Errorf("failed to create savepoint: %w", err)
+		}
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = db.rollback(ctx, s)
+			panic(p)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, s)); err != nil {
+		if rerr := db.rollback(ctx, s); rerr != nil {
+			db.log.WithContext(ctx).Errorf("failed to rollback transaction: %v", rerr)
+		}
+		return err
+	}
+	return db.commit(ctx, s)
+}
+
+// AfterCommit 注册在最外层事务提交成功后执行的回调，如缓存失效
+// 不在事务中时立即执行；所在层回滚时回调被丢弃
+func AfterCommit(ctx context.Context, fn func(context.Context)) {
+	if s, ok := ctx.Value(txKey{}).(*txState); ok {
+		s.afterCommit = append(s.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
+
+func (db *DB) commit(ctx context.Context, s *txState) error {
+	if s.parent != nil {
+		if _, err := db.exec(ctx, s.tx, "RELEASE SAVEPOINT "+s.savepoint); err != nil {
+			_ = db.rollback(ctx, s)
+			return fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		s.parent.afterCommit = append(s.parent.afterCommit, s.afterCommit...)
+		return nil
+	}
+	if err := s.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, fn := range s.afterCommit {
+		fn(ctx)
+	}
+	return nil
+}
+
+func (db *DB) rollback(ctx context.Context, s *txState) error {
+	if s.parent != nil {
+		_, err := db.exec(ctx, s.tx, "ROLLBACK TO SAVEPOINT "+s.savepoint)
+		return err
+	}
+	return s.tx.Rollback()
+}
+
+func (db *DB) exec(ctx context.Context, tx *sql.Tx, query string) (sql.Result, error) {
+	return observe(db, ctx, "exec", query, func(ctx context.Context, query string) (sql.Result, error) {
+		return tx.ExecContext(ctx, query)
+	})
+}
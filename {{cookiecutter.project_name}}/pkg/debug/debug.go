@@ -0,0 +1,220 @@
+// Package debug 提供 pprof、运行时信息、profile 采集与压测接口，应挂载在需要认证的管理端口上
+package debug
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/http/pprof"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"{{cookiecutter.project_name}}/pkg/http/response"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Reason 调试接口失败的错误原因，响应码为 response.DebugPerfFailed
+const Reason = "DEBUG_PERF_FAILED"
+
+const (
+	defaultProfileDir     = "data/profiles"
+	defaultProfileSeconds = 30
+	maxProfileSeconds     = 300
+)
+
+func init() {
+	response.RegisterReason(Reason, response.DebugPerfFailed)
+}
+
+// Option 选项
+type Option func(*Debug)
+
+// WithProfileDir profile 文件的保存目录，默认 data/profiles
+func WithProfileDir(dir string) Option {
+	return func(d *Debug) {
+		d.profileDir = dir
+	}
+}
+
+// WithEndpoint 压测目标服务的地址，未设置时不能压测
+func WithEndpoint(endpoint func() (*url.URL, error)) Option {
+	return func(d *Debug) {
+		d.endpoint = endpoint
+	}
+}
+
+// Debug 调试接口
+type Debug struct {
+	profileDir string
+	endpoint   func() (*url.URL, error)
+	started    time.Time
+	// perfRunning 同一时间只允许一个压测
+	perfRunning atomic.Bool
+	perfRuns    atomic.Int64
+}
+
+// Register 在 srv 上注册 /debug 下的接口
+//
+//	/debug/pprof/            net/http/pprof 的全部 profile
+//	GET  /debug/stats        运行时与内存统计
+//	GET  /debug/goroutines   全部 goroutine 的调用栈，?debug=1 时按调用栈合并
+//	POST /debug/profile/{name}?seconds=30  采集 cpu 或 pprof.Lookup 支持的 profile 并保存到文件
+//	POST /debug/perf         压测 WithEndpoint 服务的一个路由
+func Register(srv *http.Server, opts ...Option) *Debug {
+	d := &Debug{profileDir: defaultProfileDir, started: time.Now()}
+	for _, o := range opts {
+		o(d)
+	}
+	srv.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	srv.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	srv.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	srv.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	srv.HandlePrefix("/debug/pprof/", nethttp.HandlerFunc(pprof.Index))
+
+	r := srv.Route("/debug")
+	r.GET("/stats", d.stats)
+	r.GET("/goroutines", d.goroutines)
+	r.POST("/profile/{name}", d.profile)
+	r.POST("/perf", d.perf)
+	return d
+}
+
+// Stats 运行时统计
+type Stats struct {
+	GoVersion  string `json:"goVersion"`
+	NumCPU     int    `json:"numCPU"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	Goroutines int    `json:"goroutines"`
+	Uptime     string `json:"uptime"`
+	// 内存统计，单位字节
+	HeapAlloc   uint64 `json:"heapAlloc"`
+	HeapInuse   uint64 `json:"heapInuse"`
+	HeapObjects uint64 `json:"heapObjects"`
+	StackInuse  uint64 `json:"stackInuse"`
+	TotalAlloc  uint64 `json:"totalAlloc"`
+	Sys         uint64 `json:"sys"`
+	// GC 统计
+	NumGC         uint32    `json:"numGC"`
+	NextGC        uint64    `json:"nextGC"`
+	LastGC        time.Time `json:"lastGC"`
+	PauseTotal    string    `json:"pauseTotal"`
+	LastPause     string    `json:"lastPause"`
+	GCCPUFraction float64   `json:"gcCPUFraction"`
+}
+
+func (d *Debug) stats(ctx http.Context) error {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return ctx.Result(200, &Stats{
+		GoVersion:     runtime.Version(),
+		NumCPU:        runtime.NumCPU(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		Goroutines:    runtime.NumGoroutine(),
+		Uptime:        time.Since(d.started).Round(time.Second).String(),
+		HeapAlloc:     m.HeapAlloc,
+		HeapInuse:     m.HeapInuse,
+		HeapObjects:   m.HeapObjects,
+		StackInuse:    m.StackInuse,
+		TotalAlloc:    m.TotalAlloc,
+		Sys:           m.Sys,
+		NumGC:         m.NumGC,
+		NextGC:        m.NextGC,
+		LastGC:        time.Unix(0, int64(m.LastGC)),
+		PauseTotal:    time.Duration(m.PauseTotalNs).String(),
+		LastPause:     time.Duration(m.PauseNs[(m.NumGC+255)%256]).String(),
+		GCCPUFraction: m.GCCPUFraction,
+	})
+}
+
+func (d *Debug) goroutines(ctx http.Context) error {
+	level := 2
+	if ctx.Query().Get("debug") == "1" {
+		level = 1
+	}
+	w := ctx.Response()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	return rpprof.Lookup("goroutine").WriteTo(w, level)
+}
+
+// Profile 保存的 profile 文件
+type Profile struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Size int64  `json:"size"`
+}
+
+func (d *Debug) profile(ctx http.Context) error {
+	name := ctx.Vars().Get("name")
+	var p *rpprof.Profile
+	if name != "cpu" {
+		if p = rpprof.Lookup(name); p == nil {
+			return errors.BadRequest(Reason, fmt.Sprintf("unknown profile %q", name))
+		}
+	}
+	seconds := defaultProfileSeconds
+	if s := ctx.Query().Get("seconds"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxProfileSeconds {
+			return errors.BadRequest(Reason, fmt.Sprintf("seconds must be between 1 and %d", maxProfileSeconds))
+		}
+		seconds = n
+	}
+
+	if err := os.MkdirAll(d.profileDir, 0o755); err != nil {
+		return errors.InternalServer(Reason, "create profile dir").WithCause(err)
+	}
+	file := filepath.Join(d.profileDir, fmt.Sprintf("%s-%s.pb.gz", name, time.Now().Format("20060102-150405.000")))
+	f, err := os.Create(file)
+	if err != nil {
+		return errors.InternalServer(Reason, "create profile file").WithCause(err)
+	}
+	defer f.Close()
+
+	if p == nil {
+		err = captureCPU(ctx, f, time.Duration(seconds)*time.Second)
+	} else {
+		if name == "heap" {
+			runtime.GC()
+		}
+		err = p.WriteTo(f, 0)
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		_ = os.Remove(file)
+		if errors.FromError(err).Reason == Reason {
+			return err
+		}
+		return errors.InternalServer(Reason, "write profile").WithCause(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return errors.InternalServer(Reason, "stat profile").WithCause(err)
+	}
+	abs, _ := filepath.Abs(file)
+	return ctx.Result(200, &Profile{Name: name, File: abs, Size: info.Size()})
+}
+
+// captureCPU 采集 CPU profile，请求中断时提前结束
+func captureCPU(ctx context.Context, f *os.File, d time.Duration) error {
+	if err := rpprof.StartCPUProfile(f); err != nil {
+		return errors.Conflict(Reason, "cpu profiling is already running").WithCause(err)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+	rpprof.StopCPUProfile()
+	return nil
+}
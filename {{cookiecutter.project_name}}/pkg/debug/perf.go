@@ -0,0 +1,246 @@
+package debug
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"{{cookiecutter.project_name}}/pkg/metric"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	defaultPerfSeconds     = 10
+	maxPerfSeconds         = 300
+	defaultPerfConcurrency = 10
+	maxPerfConcurrency     = 1000
+	perfRequestTimeout     = 30 * time.Second
+)
+
+// LoadTest 压测参数
+type LoadTest struct {
+	// Method 默认 GET
+	Method string `json:"method"`
+	// Path 被压测的路径，可带查询参数，如 /api/v1/greeter/users?page.pageSize=10
+	Path   string            `json:"path"`
+	Header map[string]string `json:"header"`
+	Body   string            `json:"body"`
+	// Seconds 压测时长，默认 10，最大 300
+	Seconds int `json:"seconds"`
+	// Concurrency 并发数，默认 10，最大 1000
+	Concurrency int `json:"concurrency"`
+}
+
+// LoadReport 压测结果，耗时单位为毫秒
+type LoadReport struct {
+	Method      string  `json:"method"`
+	Path        string  `json:"path"`
+	Concurrency int     `json:"concurrency"`
+	Seconds     float64 `json:"seconds"`
+	// Requests 完成的请求数，Errors 为其中未收到响应的请求数
+	Requests    int64            `json:"requests"`
+	Errors      int64            `json:"errors"`
+	RPS         float64          `json:"rps"`
+	StatusCodes map[string]int64 `json:"statusCodes"`
+	Latency     Latency          `json:"latency"`
+}
+
+// Latency 收到响应的请求的耗时，分位数来自 metric.PerfDurationSummary
+type Latency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+func (d *Debug) perf(ctx http.Context) error {
+	var lt LoadTest
+	if err := ctx.Bind(&lt); err != nil {
+		return errors.BadRequest(Reason, "invalid load test").WithCause(err)
+	}
+	if d.endpoint == nil {
+		return errors.InternalServer(Reason, "load test endpoint is not configured")
+	}
+	base, err := d.endpoint()
+	if err != nil {
+		return errors.InternalServer(Reason, "get load test endpoint").WithCause(err)
+	}
+	if !d.perfRunning.CompareAndSwap(false, true) {
+		return errors.Conflict(Reason, "a load test is already running")
+	}
+	defer d.perfRunning.Store(false)
+	report, err := d.Run(ctx, base, lt)
+	if err != nil {
+		return err
+	}
+	return ctx.Result(200, report)
+}
+
+// Run 以 lt.Concurrency 个并发请求 base 上的 lt.Path，持续 lt.Seconds 秒
+func (d *Debug) Run(ctx context.Context, base *url.URL, lt LoadTest) (*LoadReport, error) {
+	target, err := lt.normalize(base)
+	if err != nil {
+		return nil, errors.BadRequest(Reason, err.Error())
+	}
+	run := strconv.FormatInt(d.perfRuns.Add(1), 10)
+	summary := metric.PerfDurationSummary.With(run, target.Path)
+	defer summary.Delete()
+
+	transport := &nethttp.Transport{
+		MaxIdleConnsPerHost: lt.Concurrency,
+		// 压测目标为本进程的服务，启用 TLS 时不校验其证书
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	defer transport.CloseIdleConnections()
+	client := &nethttp.Client{Transport: transport, Timeout: perfRequestTimeout}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(lt.Seconds)*time.Second)
+	defer cancel()
+	results := make([]perfResult, lt.Concurrency)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(res *perfResult) {
+			defer wg.Done()
+			res.status = make(map[int]int64)
+			for ctx.Err() == nil {
+				res.do(ctx, client, target.String(), &lt, summary)
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := &LoadReport{
+		Method:      lt.Method,
+		Path:        lt.Path,
+		Concurrency: lt.Concurrency,
+		Seconds:     elapsed.Seconds(),
+		StatusCodes: make(map[string]int64),
+	}
+	var total perfResult
+	for _, res := range results {
+		total.merge(&res)
+		for code, n := range res.status {
+			report.StatusCodes[strconv.Itoa(code)] += n
+		}
+	}
+	report.Requests = total.count + total.errors
+	report.Errors = total.errors
+	report.RPS = float64(report.Requests) / elapsed.Seconds()
+	if total.count > 0 {
+		q, _, sum := summary.Quantiles()
+		report.Latency = Latency{
+			Min:  ms(total.min.Seconds()),
+			Mean: ms(sum / float64(total.count)),
+			P50:  ms(q[0.5]),
+			P90:  ms(q[0.9]),
+			P95:  ms(q[0.95]),
+			P99:  ms(q[0.99]),
+			Max:  ms(total.max.Seconds()),
+		}
+	}
+	return report, nil
+}
+
+// normalize 填充默认值并返回压测地址，只允许压测 base 上的路径
+func (lt *LoadTest) normalize(base *url.URL) (*url.URL, error) {
+	if lt.Method == "" {
+		lt.Method = nethttp.MethodGet
+	}
+	lt.Method = strings.ToUpper(lt.Method)
+	if lt.Seconds == 0 {
+		lt.Seconds = defaultPerfSeconds
+	}
+	if lt.Concurrency == 0 {
+		lt.Concurrency = defaultPerfConcurrency
+	}
+	if lt.Seconds < 0 || lt.Seconds > maxPerfSeconds {
+		return nil, fmt.Errorf("seconds must be between 1 and %d", maxPerfSeconds)
+	}
+	if lt.Concurrency < 0 || lt.Concurrency > maxPerfConcurrency {
+		return nil, fmt.Errorf("concurrency must be between 1 and %d", maxPerfConcurrency)
+	}
+	ref, err := url.Parse(lt.Path)
+	if err != nil || !strings.HasPrefix(lt.Path, "/") || ref.Scheme != "" || ref.Host != "" {
+		return nil, fmt.Errorf("path must be an absolute path such as /api/v1/greeter/users, got %q", lt.Path)
+	}
+	return base.ResolveReference(ref), nil
+}
+
+// perfResult 单个并发的统计
+type perfResult struct {
+	count    int64
+	errors   int64
+	min, max time.Duration
+	status   map[int]int64
+}
+
+func (r *perfResult) do(ctx context.Context, client *nethttp.Client, target string, lt *LoadTest, summary metric.Summary) {
+	var body io.Reader
+	if lt.Body != "" {
+		body = strings.NewReader(lt.Body)
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, lt.Method, target, body)
+	if err != nil {
+		r.errors++
+		return
+	}
+	for k, v := range lt.Header {
+		req.Header.Set(k, v)
+	}
+	if lt.Body != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	if err != nil {
+		// 压测结束时被取消的请求不计入结果
+		if ctx.Err() == nil {
+			r.errors++
+		}
+		return
+	}
+	cost := time.Since(start)
+	summary.Observe(cost.Seconds())
+	r.count++
+	r.status[resp.StatusCode]++
+	if r.min == 0 || cost < r.min {
+		r.min = cost
+	}
+	if cost > r.max {
+		r.max = cost
+	}
+}
+
+func (r *perfResult) merge(o *perfResult) {
+	r.count += o.count
+	r.errors += o.errors
+	if o.count > 0 && (r.min == 0 || o.min < r.min) {
+		r.min = o.min
+	}
+	if o.max > r.max {
+		r.max = o.max
+	}
+}
+
+func ms(seconds float64) float64 {
+	return float64(time.Duration(seconds*float64(time.Second)).Microseconds()) / 1000
+}
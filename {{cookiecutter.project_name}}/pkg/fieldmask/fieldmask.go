@@ -0,0 +1,130 @@
+package fieldmask
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ReadMaskParam 稀疏响应的查询参数名，携带该参数的 HTTP 请求不输出零值字段
+const ReadMaskParam = "read_mask"
+
+// Paths 返回掩码中的字段路径，路径统一转换为 proto 字段名，如 "userName" 转为 "user_name"
+func Paths(mask *fieldmaskpb.FieldMask) []string {
+	paths := make([]string, 0, len(mask.GetPaths()))
+	for _, p := range mask.GetPaths() {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, toSnake(p))
+		}
+	}
+	return paths
+}
+
+// Validate 校验掩码中的路径是否均为 m 的字段
+func Validate(mask *fieldmaskpb.FieldMask, m proto.Message) error {
+	for _, p := range Paths(mask) {
+		if !valid(m.ProtoReflect().Descriptor(), p) {
+			return fmt.Errorf("unknown field %q in field mask", p)
+		}
+	}
+	return nil
+}
+
+// Prune 仅保留掩码中的字段，掩码为空时不做处理
+func Prune(m proto.Message, mask *fieldmaskpb.FieldMask) {
+	paths := Paths(mask)
+	if len(paths) == 0 {
+		return
+	}
+	prune(m.ProtoReflect(), tree(paths))
+}
+
+// Sparse 判断 HTTP 请求是否要求稀疏响应
+func Sparse(r *http.Request) bool {
+	q := r.URL.Query()
+	return q.Get(ReadMaskParam) != "" || q.Get("readMask") != ""
+}
+
+// node 字段路径树，叶子节点表示保留整个字段
+type node map[string]node
+
+func tree(paths []string) node {
+	root := node{}
+	for _, p := range paths {
+		n := root
+		parts := strings.Split(p, ".")
+		for i, part := range parts {
+			child, ok := n[part]
+			if ok && child == nil {
+				// 已保留整个字段
+				break
+			}
+			if i == len(parts)-1 {
+				n[part] = nil
+				break
+			}
+			if !ok {
+				child = node{}
+				n[part] = child
+			}
+			n = child
+		}
+	}
+	return root
+}
+
+func prune(m protoreflect.Message, keep node) {
+	var cleared []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := keep[string(fd.Name())]
+		switch {
+		case !ok:
+			cleared = append(cleared, fd)
+		case sub == nil:
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				prune(list.Get(i).Message(), sub)
+			}
+		case fd.Message() != nil && !fd.IsMap():
+			prune(v.Message(), sub)
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
+
+func valid(md protoreflect.MessageDescriptor, path string) bool {
+	name, rest, nested := strings.Cut(path, ".")
+	fd := md.Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return false
+	}
+	if !nested {
+		return true
+	}
+	if fd.Message() == nil || fd.IsMap() {
+		return false
+	}
+	return valid(fd.Message(), rest)
+}
+
+// toSnake 将 json 字段名转换为 proto 字段名
+func toSnake(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			b.WriteByte('_')
+			c += 'a' - 'A'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
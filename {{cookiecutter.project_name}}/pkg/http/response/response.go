@@ -1,39 +1,219 @@
 package response
 
-//
-//import (
-//	"net/http"
-//
-//	"github.com/gin-gonic/gin"
-//)
-//
-//type Body struct {
-//	Code    int    `json:"code"`
-//	Msg     string `json:"msg"`
-//	Data    any    `json:"data"`
-//	TraceID string `json:"traceID"`
-//}
-//
-//func Result(c *gin.Context, code int, data any, err any) {
-//	if data == nil {
-//		data = make(map[string]string, 0)
-//	}
-//	resp := Body{
-//		Code: code,
-//		Data: data,
-//		Msg:  Msg[code],
-//	}
-//
-//	if e, ok := err.(error); ok {
-//		resp.Msg = resp.Msg + ": " + e.Error()
-//	}
-//	c.JSON(http.StatusOK, resp)
-//}
-//
-//func Success(c *gin.Context, data any) {
-//	Result(c, Succ, data, nil)
-//}
-//
-//func Fail(c *gin.Context, code int, err any) {
-//	Result(c, code, nil, err)
-//}
+import (
+	"encoding/json"
+	"{{cookiecutter.project_name}}/pkg/fieldmask"
+	"{{cookiecutter.project_name}}/pkg/i18n"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	kratosjson "github.com/go-kratos/kratos/v2/encoding/json"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"github.com/go-kratos/kratos/v2/transport"
+	kratoshttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/proto"
+)
+
+// FieldViolation 参数校验失败的字段，ParamsFailed 响应的 Data 为 []FieldViolation
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Body 统一响应结构
+type Body struct {
+	Code    int    `json:"code"`
+	Msg     string `json:"msg"`
+	Data    any    `json:"data"`
+	TraceID string `json:"traceID"`
+}
+
+// Option 响应编码器选项
+type Option func(*options)
+
+type options struct {
+	rawOperations map[string]struct{}
+	rawPrefixes   []string
+}
+
+// WithRawOperation 指定不使用统一响应结构的 operation，如 helloworld.v1.Greeter/SayHello 的完整 operation 名
+func WithRawOperation(operations ...string) Option {
+	return func(o *options) {
+		for _, op := range operations {
+			o.rawOperations[op] = struct{}{}
+		}
+	}
+}
+
+// WithRawPathPrefix 指定不使用统一响应结构的路径前缀
+func WithRawPathPrefix(prefixes ...string) Option {
+	return func(o *options) {
+		o.rawPrefixes = append(o.rawPrefixes, prefixes...)
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{rawOperations: make(map[string]struct{})}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// isRaw 判断当前请求是否跳过统一响应结构
+func (o *options) isRaw(r *http.Request) bool {
+	if tr, ok := transport.FromServerContext(r.Context()); ok {
+		if _, ok := o.rawOperations[tr.Operation()]; ok {
+			return true
+		}
+	}
+	for _, p := range o.rawPrefixes {
+		if strings.HasPrefix(r.URL.Path, p) {
+			return true
+		}
+	}
+	// 仅 json 编码支持统一响应结构
+	codec, _ := kratoshttp.CodecForRequest(r, "Accept")
+	return codec.Name() != "json"
+}
+
+// ResponseEncoder 返回 kratos http.ResponseEncoder，将业务返回值包装为 Body
+func ResponseEncoder(opts ...Option) kratoshttp.EncodeResponseFunc {
+	o := newOptions(opts...)
+	return func(w http.ResponseWriter, r *http.Request, v any) error {
+		if v == nil {
+			return nil
+		}
+		if _, ok := v.(kratoshttp.Redirector); ok || o.isRaw(r) {
+			return kratoshttp.DefaultResponseEncoder(w, r, v)
+		}
+		data, err := marshal(r, v)
+		if err != nil {
+			return err
+		}
+		return write(w, http.StatusOK, Body{
+			Code:    Succ,
+			Msg:     message(i18n.FromRequest(r), Succ, ""),
+			Data:    json.RawMessage(data),
+			TraceID: traceID(r),
+		})
+	}
+}
+
+// ErrorEncoder 返回 kratos http.ErrorEncoder，将 errors.Error 映射为响应码字典中的 Body
+func ErrorEncoder(opts ...Option) kratoshttp.EncodeErrorFunc {
+	o := newOptions(opts...)
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		if o.isRaw(r) {
+			kratoshttp.DefaultErrorEncoder(w, r, err)
+			return
+		}
+		lang := i18n.FromRequest(r)
+		se := errors.FromError(i18n.Localize(lang, err))
+		code := CodeFromError(se)
+		body := Body{
+			Code:    code,
+			Msg:     message(lang, code, se.Message),
+			Data:    struct{}{},
+			TraceID: traceID(r),
+		}
+		if v := violations(code, se); len(v) > 0 {
+			body.Data = v
+		}
+		// write 仅在编码失败时返回错误，此时尚未写出响应头
+		if err := write(w, int(se.Code), body); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+// Result 直接写出统一响应结构，供未经过 kratos 编码器的原生 handler 使用
+func Result(w http.ResponseWriter, r *http.Request, code int, data any, err error) {
+	if data == nil {
+		data = struct{}{}
+	}
+	lang := i18n.FromRequest(r)
+	resp := Body{
+		Code:    code,
+		Data:    data,
+		Msg:     message(lang, code, ""),
+		TraceID: traceID(r),
+	}
+	if err != nil {
+		resp.Msg = message(lang, code, errors.FromError(i18n.Localize(lang, err)).Message)
+	}
+	_ = write(w, http.StatusOK, resp)
+}
+
+// Success 返回成功响应
+func Success(w http.ResponseWriter, r *http.Request, data any) {
+	Result(w, r, Succ, data, nil)
+}
+
+// Fail 返回失败响应
+func Fail(w http.ResponseWriter, r *http.Request, code int, err error) {
+	Result(w, r, code, nil, err)
+}
+
+// marshal 编码业务返回值，携带 read_mask 的请求不输出零值字段
+func marshal(r *http.Request, v any) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok && fieldmask.Sparse(r) {
+		opts := kratosjson.MarshalOptions
+		opts.EmitUnpopulated = false
+		return opts.Marshal(m)
+	}
+	codec, _ := kratoshttp.CodecForRequest(r, "Accept")
+	return codec.Marshal(v)
+}
+
+// violations 将参数错误 metadata 中的字段错误转换为按字段排序的列表
+func violations(code int, se *errors.Error) []FieldViolation {
+	if code != ParamsFailed {
+		return nil
+	}
+	list := make([]FieldViolation, 0, len(se.Metadata))
+	for field, msg := range se.Metadata {
+		if field != i18n.DetailKey {
+			list = append(list, FieldViolation{Field: field, Message: msg})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Field < list[j].Field })
+	return list
+}
+
+// write 编码并写出 Body，仅返回编码错误
+// 响应头写出后的写入失败通常是客户端已断开，无法再改写状态码，直接忽略
+func write(w http.ResponseWriter, status int, body Body) error {
+	data, err := encoding.GetCodec("json").Marshal(body)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+	return nil
+}
+
+// message 拼接响应码描述与错误详情，响应码描述按语言从 i18n 中获取，缺失时使用 Msg
+func message(lang i18n.Lang, code int, detail string) string {
+	msg, ok := i18n.CodeMessage(lang, code)
+	if !ok {
+		msg = Msg[code]
+	}
+	switch {
+	case msg == "":
+		return detail
+	case detail == "" || detail == msg:
+		return msg
+	default:
+		return msg + ": " + detail
+	}
+}
+
+func traceID(r *http.Request) string {
+	id, _ := tracing.TraceID()(r.Context()).(string)
+	return id
+}
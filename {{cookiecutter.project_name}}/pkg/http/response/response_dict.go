@@ -1,8 +1,16 @@
 package response
 
+import (
+	"net/http"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
 const (
 	Succ                    = 200  // 成功
 	InternalError           = 500  // 系统内部错误
+	Failed                  = 4000 // 请求失败
 	CaptchaFailed           = 4001 // 验证码获取失败
 	ParamsFailed            = 4002 // 参数校验错误
 	LoginFailed             = 4003 // 登录失败
@@ -34,11 +42,75 @@ const (
 	DebugPerfFailed         = 4029 // 性能测试失败
 )
 
+// Msg 响应码的默认描述，多语言描述见 pkg/i18n/locales
 var Msg = map[int]string{
-	Succ:          "成功",
-	InternalError: "系统内部错误",
-	CaptchaFailed: "验证码获取失败",
-	ParamsFailed:  "参数校验错误",
-	TokenFailed:   "token无效",
-	LoginFailed:   "登录失败",
+	Succ:                    "成功",
+	InternalError:           "系统内部错误",
+	Failed:                  "请求失败",
+	CaptchaFailed:           "验证码获取失败",
+	ParamsFailed:            "参数校验错误",
+	LoginFailed:             "登录失败",
+	TokenFailed:             "token无效",
+	TokenExpired:            "token授权已过期",
+	CasbinFailed:            "权限不足",
+	CaptchaVerifyFailed:     "验证码校验失败",
+	RegisterFailed:          "注册失败:用户已注册",
+	MenuListFailed:          "获取路由菜单失败",
+	CasbinAddFailed:         "权限添加失败",
+	CasbinDelFailed:         "权限删除失败",
+	CasbinUpdateFailed:      "权限更新失败",
+	CasbinListFailed:        "权限列表失败",
+	RateLimitAllowFailed:    "超出请求频率限制",
+	FileWithExcelFailed:     "文件不是excel",
+	FileReportFailed:        "文件上传失败",
+	FileOpenFailed:          "文件打开失败",
+	GetUserInfoFailed:       "获取用户信息失败",
+	UpdateUserInfoFailed:    "更新用户信息失败",
+	GetCasbinListFailed:     "获取权限表信息失败",
+	NotAdminID:              "无权限操作该接口",
+	SetCasbinFailed:         "更新权限失败",
+	GetDictListFailed:       "获取字典序失败",
+	GetSettingsFailed:       "获取layout配置失败",
+	UpdateSettingsFailed:    "设置layout配置失败",
+	TokenValidateFailed:     "token解析失败",
+	UpdatePasswordFailed:    "更新用户密码失败",
+	RateLimitAllowErrFailed: "请求频率限制接口报错",
+	DebugPerfFailed:         "性能测试失败",
+}
+
+var (
+	reasonMu sync.RWMutex
+	// reasons errors.Error 的 Reason 与响应码的映射
+	reasons = map[string]int{}
+)
+
+// RegisterReason 注册错误原因对应的响应码
+func RegisterReason(reason string, code int) {
+	reasonMu.Lock()
+	defer reasonMu.Unlock()
+	reasons[reason] = code
+}
+
+// CodeFromError 将 kratos errors.Error 映射为响应码
+// 优先按 Reason 查找，未注册时按 HTTP 状态码归类，无法归类的返回 Failed
+func CodeFromError(se *errors.Error) int {
+	reasonMu.RLock()
+	code, ok := reasons[se.Reason]
+	reasonMu.RUnlock()
+	if ok {
+		return code
+	}
+	switch {
+	case se.Code == http.StatusBadRequest:
+		return ParamsFailed
+	case se.Code == http.StatusUnauthorized:
+		return TokenFailed
+	case se.Code == http.StatusForbidden:
+		return CasbinFailed
+	case se.Code == http.StatusTooManyRequests:
+		return RateLimitAllowFailed
+	case se.Code >= http.StatusInternalServerError:
+		return InternalError
+	}
+	return Failed
 }
@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// Lang 语言标识
+type Lang string
+
+const (
+	ZhCN Lang = "zh-CN"
+	EnUS Lang = "en-US"
+
+	// Default 未指定或无法匹配时使用的语言
+	Default = ZhCN
+
+	// DetailKey errors.Error metadata 中不需要翻译的错误详情
+	DetailKey = "detail"
+)
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// bundle 单个语言的消息集合
+type bundle struct {
+	Codes   map[int]string    `yaml:"codes"`
+	Reasons map[string]string `yaml:"reasons"`
+}
+
+var (
+	mu      sync.RWMutex
+	bundles = map[Lang]*bundle{}
+	// langs 与 matcher 的候选顺序一致，默认语言位于首位
+	langs   = []Lang{Default}
+	matcher = language.NewMatcher([]language.Tag{language.Make(string(Default))})
+)
+
+func init() {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		data, err := locales.ReadFile(path.Join("locales", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		if err := Load(Lang(strings.TrimSuffix(e.Name(), path.Ext(e.Name()))), data); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Load 加载 yaml 格式的语言包，已存在的语言会合并覆盖
+func Load(lang Lang, data []byte) error {
+	var b bundle
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return fmt.Errorf("failed to parse i18n bundle %s: %w", lang, err)
+	}
+	if _, err := language.Parse(string(lang)); err != nil {
+		return fmt.Errorf("invalid i18n language %s: %w", lang, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	old, ok := bundles[lang]
+	if !ok {
+		old = &bundle{Codes: map[int]string{}, Reasons: map[string]string{}}
+		bundles[lang] = old
+		if lang != Default {
+			langs = append(langs, lang)
+			tags := make([]language.Tag, 0, len(langs))
+			for _, l := range langs {
+				tags = append(tags, language.Make(string(l)))
+			}
+			matcher = language.NewMatcher(tags)
+		}
+	}
+	for k, v := range b.Codes {
+		old.Codes[k] = v
+	}
+	for k, v := range b.Reasons {
+		old.Reasons[k] = v
+	}
+	return nil
+}
+
+// Match 根据 Accept-Language 的值选择最合适的语言
+func Match(acceptLanguage string) Lang {
+	if acceptLanguage == "" {
+		return Default
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	_, idx, c := matcher.Match(tags...)
+	if c == language.No {
+		return Default
+	}
+	return langs[idx]
+}
+
+// CodeMessage 获取响应码在指定语言下的描述
+func CodeMessage(lang Lang, code int) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if b, ok := bundles[lang]; ok {
+		if msg, ok := b.Codes[code]; ok {
+			return msg, true
+		}
+	}
+	if b, ok := bundles[Default]; ok {
+		msg, ok := b.Codes[code]
+		return msg, ok
+	}
+	return "", false
+}
+
+// ReasonMessage 获取错误原因在指定语言下的描述
+func ReasonMessage(lang Lang, reason string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if b, ok := bundles[lang]; ok {
+		if msg, ok := b.Reasons[reason]; ok {
+			return msg, true
+		}
+	}
+	if b, ok := bundles[Default]; ok {
+		msg, ok := b.Reasons[reason]
+		return msg, ok
+	}
+	return "", false
+}
+
+// Localize 按错误原因替换 errors.Error 的描述，未收录的错误原样返回
+// metadata 中的 DetailKey 会附加在翻译后的描述之后
+func Localize(lang Lang, err error) error {
+	if err == nil {
+		return nil
+	}
+	se := errors.FromError(err)
+	msg, ok := ReasonMessage(lang, se.Reason)
+	if detail := se.Metadata[DetailKey]; ok && detail != "" {
+		msg += ": " + detail
+	}
+	if !ok || msg == se.Message {
+		return err
+	}
+	le := errors.Clone(se)
+	le.Message = msg
+	return le
+}
@@ -0,0 +1,54 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// HeaderKey HTTP header 与 gRPC metadata 中携带语言的键
+const HeaderKey = "Accept-Language"
+
+type langKey struct{}
+
+// NewContext 将语言写入 context
+func NewContext(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// FromContext 从 context 中获取语言，未设置时返回默认语言
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(langKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// FromRequest 从 HTTP 请求中获取语言
+func FromRequest(r *http.Request) Lang {
+	if lang, ok := r.Context().Value(langKey{}).(Lang); ok {
+		return lang
+	}
+	return Match(r.Header.Get(HeaderKey))
+}
+
+// Server 服务端国际化中间件，HTTP 与 gRPC 通用
+// 根据 Accept-Language 选择语言写入 context，并翻译返回的 errors.Error 描述
+func Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			lang := Default
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				lang = Match(tr.RequestHeader().Get(HeaderKey))
+			}
+			ctx = NewContext(ctx, lang)
+			reply, err := handler(ctx, req)
+			if err != nil {
+				return nil, Localize(lang, err)
+			}
+			return reply, nil
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ID 布局：1 位符号位 | 41 位毫秒时间戳 | 10 位 worker id | 12 位序列号
+// 41 位时间戳自 Epoch 起可用约 69 年，单个 worker 每毫秒最多生成 4096 个 ID
+const (
+	workerBits   = 10
+	sequenceBits = 12
+	timeBits     = 41
+
+	// MaxWorker 最大的 worker id
+	MaxWorker   = 1<<workerBits - 1
+	maxSequence = 1<<sequenceBits - 1
+	maxElapsed  = 1<<timeBits - 1
+
+	defaultMaxBackwards = 50 * time.Millisecond
+)
+
+// Epoch 默认的起始时间，修改后生成的 ID 与之前的不可比较
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	// ErrClockBackwards 系统时钟回拨超过允许的范围
+	ErrClockBackwards = errors.New("idgen: clock moved backwards")
+	// ErrLeaseLost worker id 租约已失效，重新获取前不生成 ID
+	ErrLeaseLost = errors.New("idgen: worker lease lost")
+	// ErrInvalidID ID 格式错误
+	ErrInvalidID = errors.New("idgen: invalid id")
+)
+
+// ID 按时间递增的 64 位 ID
+type ID int64
+
+// Int64 返回 ID 的整数形式
+func (id ID) Int64() int64 {
+	return int64(id)
+}
+
+// String 返回十进制形式，JSON 中应以字符串传递以免超出 JavaScript 的安全整数范围
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// Base62 返回 base62 形式，最长 11 个字符
+func (id ID) Base62() string {
+	return encodeBase62(uint64(id))
+}
+
+// Parse 解析十进制形式的 ID
+func Parse(s string) (ID, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	return ID(n), nil
+}
+
+// ParseBase62 解析 base62 形式的 ID
+func ParseBase62(s string) (ID, error) {
+	n, err := decodeBase62(s)
+	if err != nil || n > 1<<63-1 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	return ID(n), nil
+}
+
+// Option 生成器选项
+type Option func(*Generator)
+
+// WithEpoch 设置起始时间，同一业务的所有实例必须一致
+func WithEpoch(epoch time.Time) Option {
+	return func(g *Generator) {
+		g.epoch = epoch
+	}
+}
+
+// WithMaxBackwards 设置允许等待的时钟回拨时长，默认 50ms，超过时 NextID 返回 ErrClockBackwards
+func WithMaxBackwards(d time.Duration) Option {
+	return func(g *Generator) {
+		g.maxBackwards = d
+	}
+}
+
+// Generator snowflake 风格的 ID 生成器，并发安全
+type Generator struct {
+	mu           sync.Mutex
+	epoch        time.Time
+	maxBackwards time.Duration
+	worker       func() (int64, bool)
+	lastWorker   int64
+	last         int64 // 上次生成 ID 的毫秒时间戳，相对 epoch
+	sequence     int64
+	now          func() time.Time
+}
+
+// New 使用固定的 worker id 创建生成器，worker 的取值范围为 [0, MaxWorker]
+func New(worker int64, opts ...Option) (*Generator, error) {
+	if worker < 0 || worker > MaxWorker {
+		return nil, fmt.Errorf("idgen: worker id %d out of range [0, %d]", worker, MaxWorker)
+	}
+	return newGenerator(func() (int64, bool) { return worker, true }, opts), nil
+}
+
+// NewWithLease 使用租约中的 worker id 创建生成器，租约失效期间 NextID 返回 ErrLeaseLost
+func NewWithLease(lease *Lease, opts ...Option) *Generator {
+	return newGenerator(lease.Worker, opts)
+}
+
+func newGenerator(worker func() (int64, bool), opts []Option) *Generator {
+	g := &Generator{
+		epoch:        Epoch,
+		maxBackwards: defaultMaxBackwards,
+		worker:       worker,
+		lastWorker:   -1,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// NextID 生成下一个 ID
+func (g *Generator) NextID() (ID, error) {
+	worker, ok := g.worker()
+	if !ok {
+		return 0, ErrLeaseLost
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if worker != g.lastWorker {
+		// worker id 变化后序列号重新计数
+		g.lastWorker, g.sequence = worker, -1
+	}
+	now := g.elapsed()
+	if now < g.last {
+		backwards := time.Duration(g.last-now) * time.Millisecond
+		if backwards > g.maxBackwards {
+			return 0, fmt.Errorf("%w by %s", ErrClockBackwards, backwards)
+		}
+		time.Sleep(backwards)
+		if now = g.elapsed(); now < g.last {
+			return 0, fmt.Errorf("%w by %s", ErrClockBackwards, time.Duration(g.last-now)*time.Millisecond)
+		}
+	}
+	if now == g.last {
+		if g.sequence++; g.sequence > maxSequence {
+			// 当前毫秒的序列号已用完，等待下一毫秒
+			for now <= g.last {
+				time.Sleep(100 * time.Microsecond)
+				now = g.elapsed()
+			}
+			g.sequence = 0
+		}
+	} else {
+		g.sequence = 0
+	}
+	if now > maxElapsed {
+		return 0, fmt.Errorf("idgen: timestamp overflows %d bits since epoch %s", timeBits, g.epoch.Format(time.DateOnly))
+	}
+	if now < 0 {
+		return 0, fmt.Errorf("idgen: clock is before epoch %s", g.epoch.Format(time.DateOnly))
+	}
+	g.last = now
+	return ID(now<<(workerBits+sequenceBits) | worker<<sequenceBits | g.sequence), nil
+}
+
+// Time 返回 ID 的生成时间
+func (g *Generator) Time(id ID) time.Time {
+	return g.epoch.Add(time.Duration(int64(id)>>(workerBits+sequenceBits)) * time.Millisecond)
+}
+
+// Worker 返回 ID 中的 worker id
+func Worker(id ID) int64 {
+	return int64(id) >> sequenceBits & MaxWorker
+}
+
+func (g *Generator) elapsed() int64 {
+	return g.now().Sub(g.epoch).Milliseconds()
+}
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+func encodeBase62(n uint64) string {
+	if n == 0 {
+		return "0"
+	}
+	var buf [11]byte
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = base62[n%62]
+		n /= 62
+	}
+	return string(buf[i:])
+}
+
+func decodeBase62(s string) (uint64, error) {
+	if s == "" || len(s) > 11 {
+		return 0, ErrInvalidID
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		var d uint64
+		switch {
+		case c >= '0' && c <= '9':
+			d = uint64(c - '0')
+		case c >= 'A' && c <= 'Z':
+			d = uint64(c-'A') + 10
+		case c >= 'a' && c <= 'z':
+			d = uint64(c-'a') + 36
+		default:
+			return 0, ErrInvalidID
+		}
+		if n > (1<<64-1-d)/62 {
+			return 0, ErrInvalidID
+		}
+		n = n*62 + d
+	}
+	return n, nil
+}
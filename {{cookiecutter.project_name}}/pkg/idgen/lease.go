@@ -0,0 +1,201 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const defaultLeaseTTL = 30 * time.Second
+
+// ErrNoWorker 所有 worker id 均已被占用
+var ErrNoWorker = errors.New("idgen: no free worker id")
+
+// WorkerFromConfig 由配置推导 worker id
+// 数字直接作为 worker id，IPv4 地址取低 10 位，其他字符串取哈希，仅适用于能保证不冲突的部署
+func WorkerFromConfig(s string) int64 {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n >= 0 && n <= MaxWorker {
+		return n
+	}
+	if ip := net.ParseIP(s).To4(); ip != nil {
+		return int64(ip[2])<<8&MaxWorker | int64(ip[3])
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return int64(h.Sum32() % (MaxWorker + 1))
+}
+
+// Store 保存 worker id 租约的共享存储
+type Store interface {
+	// Acquire 尝试占用 worker id，已被其他实例占用时返回 false
+	Acquire(ctx context.Context, worker int64, ttl time.Duration) (bool, error)
+	// Renew 续约，租约已不属于当前实例时返回 false
+	Renew(ctx context.Context, worker int64, ttl time.Duration) (bool, error)
+	// Release 释放租约，租约不属于当前实例时不做处理
+	Release(ctx context.Context, worker int64) error
+}
+
+// LeaseOption 租约选项
+type LeaseOption func(*Lease)
+
+// WithTTL 设置租约时长，默认 30s，每 1/3 租约时长续约一次
+func WithTTL(ttl time.Duration) LeaseOption {
+	return func(l *Lease) {
+		if ttl > 0 {
+			l.ttl = ttl
+		}
+	}
+}
+
+// WithPreferred 设置优先尝试的 worker id，通常为 WorkerFromConfig 的结果，使重启后尽量复用同一个 worker id
+func WithPreferred(worker int64) LeaseOption {
+	return func(l *Lease) {
+		l.preferred = worker & MaxWorker
+	}
+}
+
+// WithLogger 设置日志
+func WithLogger(logger log.Logger) LeaseOption {
+	return func(l *Lease) {
+		l.log = log.NewHelper(logger)
+	}
+}
+
+// Lease 从 Store 租用的 worker id，后台自动续约
+// 续约失败且租约到期后 Worker 返回 false，并在后台重新租用
+type Lease struct {
+	store     Store
+	ttl       time.Duration
+	preferred int64
+	log       *log.Helper
+
+	mu      sync.Mutex
+	worker  int64
+	expires atomic.Int64 // 租约到期时间，unix 纳秒
+	current atomic.Int64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewLease 租用一个 worker id，失败时返回错误
+func NewLease(ctx context.Context, store Store, opts ...LeaseOption) (*Lease, error) {
+	l := &Lease{
+		store:  store,
+		ttl:    defaultLeaseTTL,
+		worker: -1,
+		log:    log.NewHelper(log.GetLogger()),
+		done:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	var keepCtx context.Context
+	keepCtx, l.cancel = context.WithCancel(context.Background())
+	go l.keepAlive(keepCtx)
+	return l, nil
+}
+
+// Worker 返回当前的 worker id，租约已失效时返回 false
+// 到期前预留 1/3 租约时长，避免与其他实例的时钟误差导致重复
+func (l *Lease) Worker() (int64, bool) {
+	if time.Now().UnixNano() >= l.expires.Load()-int64(l.ttl/3) {
+		return 0, false
+	}
+	return l.current.Load(), true
+}
+
+// Close 停止续约并释放租约
+func (l *Lease) Close(ctx context.Context) error {
+	l.cancel()
+	<-l.done
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.worker < 0 {
+		return nil
+	}
+	l.expires.Store(0)
+	err := l.store.Release(ctx, l.worker)
+	l.worker = -1
+	return err
+}
+
+// acquire 从 preferred 开始依次尝试所有 worker id
+func (l *Lease) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := int64(0); i <= MaxWorker; i++ {
+		worker := (l.preferred + i) & MaxWorker
+		start := time.Now()
+		ok, err := l.store.Acquire(ctx, worker, l.ttl)
+		if err != nil {
+			return fmt.Errorf("idgen: acquire worker %d: %w", worker, err)
+		}
+		if ok {
+			l.worker = worker
+			l.current.Store(worker)
+			l.expires.Store(start.Add(l.ttl).UnixNano())
+			l.log.Infof("idgen: leased worker id %d", worker)
+			return nil
+		}
+	}
+	return ErrNoWorker
+}
+
+func (l *Lease) keepAlive(ctx context.Context) {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := l.renew(ctx); err != nil && ctx.Err() == nil {
+			l.log.Errorf("idgen: renew worker lease: %v", err)
+		}
+	}
+}
+
+func (l *Lease) renew(ctx context.Context) error {
+	l.mu.Lock()
+	worker := l.worker
+	l.mu.Unlock()
+	if worker >= 0 {
+		start := time.Now()
+		ok, err := l.store.Renew(ctx, worker, l.ttl)
+		if err != nil {
+			return err
+		}
+		if ok {
+			l.expires.Store(start.Add(l.ttl).UnixNano())
+			return nil
+		}
+		l.log.Warnf("idgen: worker id %d was taken over, leasing a new one", worker)
+		l.mu.Lock()
+		l.worker = -1
+		l.mu.Unlock()
+		l.expires.Store(0)
+	}
+	return l.acquire(ctx)
+}
+
+// instanceID 当前进程的唯一标识，作为租约的持有者
+func instanceID() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d-%d", host, os.Getpid(), time.Now().UnixNano())
+}
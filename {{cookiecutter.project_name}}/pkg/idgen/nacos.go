@@ -0,0 +1,145 @@
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	metaWorker = "idgen.worker"
+	metaOwner  = "idgen.owner"
+
+	defaultSettle = time.Second
+)
+
+// NacosStore 使用 Nacos 临时实例保存租约
+// 每个进程在 service 下注册一个临时实例，metadata 中记录 worker id，连接断开后实例自动下线即租约释放
+// 注册后等待实例列表同步，多个实例声明同一 worker id 时实例标识较小者胜出
+type NacosStore struct {
+	client   naming_client.INamingClient
+	service  string
+	group    string
+	ip       string
+	port     uint64
+	instance string
+	settle   time.Duration
+}
+
+// NewNacosStore 创建 Nacos 租约存储，service 通常为 "{app}-idgen"，ip 为当前实例地址
+func NewNacosStore(client naming_client.INamingClient, service, group, ip string) *NacosStore {
+	return &NacosStore{
+		client:  client,
+		service: service,
+		group:   group,
+		ip:      ip,
+		// 同一主机上的进程以 pid 区分，避免实例互相覆盖
+		port:     uint64(os.Getpid()%65535 + 1),
+		instance: instanceID(),
+		settle:   defaultSettle,
+	}
+}
+
+// Acquire 实现 Store
+func (s *NacosStore) Acquire(ctx context.Context, worker int64, ttl time.Duration) (bool, error) {
+	instances, err := s.instances()
+	if err != nil {
+		return false, err
+	}
+	if s.taken(instances, worker, false) {
+		return false, nil
+	}
+
+	_, err = s.client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip:          s.ip,
+		Port:        s.port,
+		Weight:      1,
+		Enable:      true,
+		Healthy:     true,
+		Ephemeral:   true,
+		ServiceName: s.service,
+		GroupName:   s.group,
+		Metadata: map[string]string{
+			metaWorker: strconv.FormatInt(worker, 10),
+			metaOwner:  s.instance,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("register nacos instance: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-time.After(s.settle):
+	}
+	return s.Renew(ctx, worker, ttl)
+}
+
+// Renew 实现 Store，实例的心跳由 Nacos 客户端维持，这里仅确认租约仍属于当前实例
+func (s *NacosStore) Renew(ctx context.Context, worker int64, ttl time.Duration) (bool, error) {
+	instances, err := s.instances()
+	if err != nil {
+		return false, err
+	}
+	if !s.owned(instances, worker) {
+		return false, nil
+	}
+	if s.taken(instances, worker, true) {
+		return false, s.Release(ctx, worker)
+	}
+	return true, nil
+}
+
+// Release 实现 Store
+func (s *NacosStore) Release(ctx context.Context, worker int64) error {
+	_, err := s.client.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          s.ip,
+		Port:        s.port,
+		ServiceName: s.service,
+		GroupName:   s.group,
+		Ephemeral:   true,
+	})
+	return err
+}
+
+func (s *NacosStore) instances() ([]model.Instance, error) {
+	instances, err := s.client.SelectAllInstances(vo.SelectAllInstancesParam{
+		ServiceName: s.service,
+		GroupName:   s.group,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list nacos instances: %w", err)
+	}
+	return instances, nil
+}
+
+// owned 当前实例是否已注册且声明了 worker
+func (s *NacosStore) owned(instances []model.Instance, worker int64) bool {
+	for _, in := range instances {
+		if in.Metadata[metaOwner] == s.instance {
+			return in.Metadata[metaWorker] == strconv.FormatInt(worker, 10)
+		}
+	}
+	return false
+}
+
+// taken 其他实例是否声明了 worker，contended 为 true 时仅统计实例标识更小的竞争者
+func (s *NacosStore) taken(instances []model.Instance, worker int64, contended bool) bool {
+	for _, in := range instances {
+		owner := in.Metadata[metaOwner]
+		if owner == s.instance || in.Metadata[metaWorker] != strconv.FormatInt(worker, 10) {
+			continue
+		}
+		if !contended || owner < s.instance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisStore 使用 Redis 键保存租约，键为 prefix:worker，值为实例标识
+type RedisStore struct {
+	rdb      redis.UniversalClient
+	prefix   string
+	instance string
+}
+
+// NewRedisStore 创建 Redis 租约存储，prefix 通常包含应用名，如 "idgen:{app}:worker"
+func NewRedisStore(rdb redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{rdb: rdb, prefix: prefix, instance: instanceID()}
+}
+
+// Acquire 实现 Store
+func (s *RedisStore) Acquire(ctx context.Context, worker int64, ttl time.Duration) (bool, error) {
+	return s.rdb.SetNX(ctx, s.key(worker), s.instance, ttl).Result()
+}
+
+// Renew 实现 Store
+func (s *RedisStore) Renew(ctx context.Context, worker int64, ttl time.Duration) (bool, error) {
+	n, err := renewScript.Run(ctx, s.rdb, []string{s.key(worker)}, s.instance, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// Release 实现 Store
+func (s *RedisStore) Release(ctx context.Context, worker int64) error {
+	return releaseScript.Run(ctx, s.rdb, []string{s.key(worker)}, s.instance).Err()
+}
+
+func (s *RedisStore) key(worker int64) string {
+	return fmt.Sprintf("%s:%d", s.prefix, worker)
+}
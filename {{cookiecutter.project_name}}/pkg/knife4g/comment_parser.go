@@ -51,8 +51,8 @@ func (p *CommentParser) Parse(comment string) *CommentParser {
 
 			// 根据标签类型进行不同的处理
 			switch tag {
-			case "enum":
-				// 处理枚举值
+			case "enum", "errors":
+				// 处理枚举值、错误原因列表
 				value = strings.Trim(value, "[]")
 				values := strings.Split(value, ",")
 				for i, v := range values {
@@ -0,0 +1,417 @@
+package knife4g
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	openapiv3 "github.com/google/gnostic/openapiv3"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// schemaPrefix 组件引用前缀，schema 名称为消息的完整名称，与 fq_schema_naming=true 生成的文档一致
+const schemaPrefix = "#/components/schemas/"
+
+// maxQueryDepth 查询参数展开嵌套消息的最大层数
+const maxQueryDepth = 3
+
+// pathParam 匹配路径模板中的变量，如 {id}、{name=shelves/*}
+var pathParam = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+// scalarSchemas 标量类型对应的 schema，64 位整数按 protojson 编码为字符串
+var scalarSchemas = map[protoreflect.Kind]Schema{
+	protoreflect.BoolKind:     {Type: "boolean"},
+	protoreflect.Int32Kind:    {Type: "integer", Format: "int32"},
+	protoreflect.Sint32Kind:   {Type: "integer", Format: "int32"},
+	protoreflect.Sfixed32Kind: {Type: "integer", Format: "int32"},
+	protoreflect.Uint32Kind:   {Type: "integer", Format: "uint32"},
+	protoreflect.Fixed32Kind:  {Type: "integer", Format: "uint32"},
+	protoreflect.Int64Kind:    {Type: "string", Format: "int64"},
+	protoreflect.Sint64Kind:   {Type: "string", Format: "int64"},
+	protoreflect.Sfixed64Kind: {Type: "string", Format: "int64"},
+	protoreflect.Uint64Kind:   {Type: "string", Format: "uint64"},
+	protoreflect.Fixed64Kind:  {Type: "string", Format: "uint64"},
+	protoreflect.FloatKind:    {Type: "number", Format: "float"},
+	protoreflect.DoubleKind:   {Type: "number", Format: "double"},
+	protoreflect.StringKind:   {Type: "string"},
+	protoreflect.BytesKind:    {Type: "string", Format: "bytes"},
+}
+
+// wellKnownSchemas 常用 Well-Known Types 按 protojson 编码后的 schema
+var wellKnownSchemas = map[protoreflect.FullName]Schema{
+	"google.protobuf.Timestamp":   {Type: "string", Format: "date-time"},
+	"google.protobuf.Duration":    {Type: "string"},
+	"google.protobuf.FieldMask":   {Type: "string", Format: "field-mask"},
+	"google.protobuf.Struct":      {Type: "object"},
+	"google.protobuf.Value":       {},
+	"google.protobuf.ListValue":   {Type: "array", Items: &Schema{}},
+	"google.protobuf.Empty":       {Type: "object"},
+	"google.protobuf.Any":         {Type: "object"},
+	"google.protobuf.BoolValue":   {Type: "boolean"},
+	"google.protobuf.Int32Value":  {Type: "integer", Format: "int32"},
+	"google.protobuf.UInt32Value": {Type: "integer", Format: "uint32"},
+	"google.protobuf.Int64Value":  {Type: "string", Format: "int64"},
+	"google.protobuf.UInt64Value": {Type: "string", Format: "uint64"},
+	"google.protobuf.FloatValue":  {Type: "number", Format: "float"},
+	"google.protobuf.DoubleValue": {Type: "number", Format: "double"},
+	"google.protobuf.StringValue": {Type: "string"},
+	"google.protobuf.BytesValue":  {Type: "string", Format: "bytes"},
+}
+
+// LoadOpenAPIFromDescriptors 根据已注册的 proto 描述生成 OpenAPI 配置
+// 路由取自 google.api.http 注解，文档信息、操作与字段说明取自 openapi.v3 注解
+// 编译后的描述不含源码注释，注释中的说明（包括 @errors）只出现在 make api 生成的文档中
+func LoadOpenAPIFromDescriptors(files *protoregistry.Files) (*OpenAPI3, error) {
+	b := &descriptorBuilder{
+		doc: &OpenAPI3{
+			OpenAPI:    "3.0.3",
+			Paths:      make(map[string]PathItem),
+			Components: Components{Schemas: make(map[string]Schema)},
+		},
+		seen: make(map[protoreflect.FullName]struct{}),
+	}
+
+	var fds []protoreflect.FileDescriptor
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		fds = append(fds, fd)
+		return true
+	})
+	sort.Slice(fds, func(i, j int) bool { return fds[i].Path() < fds[j].Path() })
+
+	var services []protoreflect.ServiceDescriptor
+	for _, fd := range fds {
+		if doc, ok := proto.GetExtension(fd.Options(), openapiv3.E_Document).(*openapiv3.Document); ok && doc.GetInfo() != nil && b.doc.Info.Title == "" {
+			b.doc.Info.Title = doc.GetInfo().GetTitle()
+			b.doc.Info.Description = doc.GetInfo().GetDescription()
+			b.doc.Info.Version = doc.GetInfo().GetVersion()
+		}
+		for i := 0; i < fd.Services().Len(); i++ {
+			services = append(services, fd.Services().Get(i))
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].FullName() < services[j].FullName() })
+
+	for _, sd := range services {
+		var found bool
+		for i := 0; i < sd.Methods().Len(); i++ {
+			md := sd.Methods().Get(i)
+			rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if !ok || rule == nil {
+				continue
+			}
+			for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				found = b.addOperation(sd, md, r) || found
+			}
+		}
+		if found {
+			b.doc.Tags = append(b.doc.Tags, Tag{Name: string(sd.Name())})
+		}
+	}
+	if len(b.doc.Paths) == 0 {
+		return nil, errors.New("no method with google.api.http annotation is registered")
+	}
+
+	// 生成操作引用到的消息，生成过程中可能引用新的消息
+	for len(b.pending) > 0 {
+		msg := b.pending[0]
+		b.pending = b.pending[1:]
+		b.doc.Components.Schemas[string(msg.FullName())] = b.messageSchema(msg)
+	}
+	if b.doc.Info.Version == "" {
+		b.doc.Info.Version = "0.0.1"
+	}
+	return b.doc, nil
+}
+
+// descriptorBuilder 由 proto 描述生成 OpenAPI 配置
+type descriptorBuilder struct {
+	doc *OpenAPI3
+	// seen 已引用的消息，pending 为其中尚未生成 schema 的消息
+	seen    map[protoreflect.FullName]struct{}
+	pending []protoreflect.MessageDescriptor
+}
+
+// addOperation 添加 rule 对应的操作，不支持的 HTTP 方法返回 false
+func (b *descriptorBuilder) addOperation(sd protoreflect.ServiceDescriptor, md protoreflect.MethodDescriptor, rule *annotations.HttpRule) bool {
+	method, template := httpPattern(rule)
+	switch method {
+	case "GET", "PUT", "POST", "DELETE", "PATCH":
+	default:
+		return false
+	}
+	op := &Operation{
+		Tags:        []string{string(sd.Name())},
+		OperationID: string(sd.Name()) + "_" + string(md.Name()),
+		Responses:   make(map[string]Response),
+	}
+	if o, ok := proto.GetExtension(md.Options(), openapiv3.E_Operation).(*openapiv3.Operation); ok && o != nil {
+		if len(o.GetTags()) > 0 {
+			op.Tags = o.GetTags()
+		}
+		if o.GetOperationId() != "" {
+			op.OperationID = o.GetOperationId()
+		}
+		op.Summary = o.GetSummary()
+		op.Description = o.GetDescription()
+		op.Deprecated = o.GetDeprecated()
+	}
+	if opts, ok := md.Options().(*descriptorpb.MethodOptions); ok && opts.GetDeprecated() {
+		op.Deprecated = true
+	}
+
+	input := md.Input()
+	exclude := make(map[string]struct{})
+	path := pathParam.ReplaceAllStringFunc(template, func(s string) string {
+		name := pathParam.FindStringSubmatch(s)[1]
+		exclude[name] = struct{}{}
+		param := Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		if fd := findField(input, name); fd != nil {
+			param.Schema = b.fieldSchema(fd)
+			param.Description = param.Schema.Description
+		}
+		op.Parameters = append(op.Parameters, param)
+		return "{" + name + "}"
+	})
+
+	switch body := rule.GetBody(); body {
+	case "":
+		op.Parameters = append(op.Parameters, b.queryParameters(input, "", "", exclude, 0)...)
+	case "*":
+		op.RequestBody = jsonBody(b.ref(input))
+	default:
+		exclude[body] = struct{}{}
+		if fd := input.Fields().ByName(protoreflect.Name(body)); fd != nil {
+			op.RequestBody = jsonBody(b.fieldSchema(fd))
+		}
+		op.Parameters = append(op.Parameters, b.queryParameters(input, "", "", exclude, 0)...)
+	}
+
+	output := b.ref(md.Output())
+	if rb := rule.GetResponseBody(); rb != "" {
+		if fd := md.Output().Fields().ByName(protoreflect.Name(rb)); fd != nil {
+			output = b.fieldSchema(fd)
+		}
+	}
+	op.Responses["200"] = Response{
+		Description: "OK",
+		Content:     map[string]MediaType{"application/json": {Schema: output}},
+	}
+
+	item := b.doc.Paths[path]
+	switch method {
+	case "GET":
+		item.Get = op
+	case "PUT":
+		item.Put = op
+	case "POST":
+		item.Post = op
+	case "DELETE":
+		item.Delete = op
+	case "PATCH":
+		item.Patch = op
+	}
+	b.doc.Paths[path] = item
+	return true
+}
+
+// queryParameters 将未绑定到路径与请求体的字段展开为查询参数，嵌套消息展开为 page.pageSize 形式
+// exclude 中为 proto 字段名路径，参数名使用 JSON 名称
+func (b *descriptorBuilder) queryParameters(msg protoreflect.MessageDescriptor, protoPrefix, jsonPrefix string, exclude map[string]struct{}, depth int) []Parameter {
+	var params []Parameter
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		protoName := protoPrefix + string(fd.Name())
+		jsonName := jsonPrefix + fd.JSONName()
+		if _, ok := exclude[protoName]; ok || fd.IsMap() {
+			continue
+		}
+		if fd.Message() != nil {
+			if _, ok := wellKnownSchemas[fd.Message().FullName()]; !ok {
+				if !fd.IsList() && depth < maxQueryDepth {
+					params = append(params, b.queryParameters(fd.Message(), protoName+".", jsonName+".", exclude, depth+1)...)
+				}
+				continue
+			}
+		}
+		schema := b.fieldSchema(fd)
+		params = append(params, Parameter{
+			Name:        jsonName,
+			In:          "query",
+			Description: schema.Description,
+			Deprecated:  schema.Deprecated,
+			Schema:      schema,
+		})
+	}
+	return params
+}
+
+// messageSchema 生成消息的 schema，属性名为字段的 JSON 名称
+func (b *descriptorBuilder) messageSchema(msg protoreflect.MessageDescriptor) Schema {
+	schema := Schema{Type: "object", Properties: make(map[string]*Schema)}
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		schema.Properties[fd.JSONName()] = b.fieldSchema(fd)
+	}
+	if o, ok := proto.GetExtension(msg.Options(), openapiv3.E_Schema).(*openapiv3.Schema); ok {
+		applySchemaOptions(&schema, o)
+	}
+	return schema
+}
+
+// fieldSchema 生成字段的 schema，并合并字段的 openapi.v3.property 注解
+func (b *descriptorBuilder) fieldSchema(fd protoreflect.FieldDescriptor) *Schema {
+	var schema *Schema
+	switch {
+	case fd.IsMap():
+		schema = &Schema{Type: "object", AdditionalProperties: b.valueSchema(fd.MapValue())}
+	case fd.IsList():
+		schema = &Schema{Type: "array", Items: b.valueSchema(fd)}
+	default:
+		schema = b.valueSchema(fd)
+	}
+	if o, ok := proto.GetExtension(fd.Options(), openapiv3.E_Property).(*openapiv3.Schema); ok {
+		applySchemaOptions(schema, o)
+	}
+	if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDeprecated() {
+		schema.Deprecated = true
+	}
+	return schema
+}
+
+// valueSchema 生成单个值的 schema，忽略 repeated 与 map
+func (b *descriptorBuilder) valueSchema(fd protoreflect.FieldDescriptor) *Schema {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		schema := &Schema{Type: "string", Format: "enum", Enum: make([]interface{}, 0, values.Len())}
+		for i := 0; i < values.Len(); i++ {
+			schema.Enum = append(schema.Enum, string(values.Get(i).Name()))
+		}
+		return schema
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if s, ok := wellKnownSchemas[fd.Message().FullName()]; ok {
+			return &s
+		}
+		return b.ref(fd.Message())
+	default:
+		s := scalarSchemas[fd.Kind()]
+		return &s
+	}
+}
+
+// ref 返回消息的引用，首次引用的消息加入待生成列表
+func (b *descriptorBuilder) ref(msg protoreflect.MessageDescriptor) *Schema {
+	if s, ok := wellKnownSchemas[msg.FullName()]; ok {
+		return &s
+	}
+	if _, ok := b.seen[msg.FullName()]; !ok {
+		b.seen[msg.FullName()] = struct{}{}
+		b.pending = append(b.pending, msg)
+	}
+	return &Schema{Ref: schemaPrefix + string(msg.FullName())}
+}
+
+// applySchemaOptions 将 openapi.v3.schema 或 openapi.v3.property 注解合并到 schema
+func applySchemaOptions(schema *Schema, o *openapiv3.Schema) {
+	if o == nil {
+		return
+	}
+	if o.GetType() != "" {
+		schema.Type = o.GetType()
+	}
+	if o.GetFormat() != "" {
+		schema.Format = o.GetFormat()
+	}
+	if o.GetTitle() != "" {
+		schema.Title = o.GetTitle()
+	}
+	if o.GetDescription() != "" {
+		schema.Description = o.GetDescription()
+	}
+	if o.GetPattern() != "" {
+		schema.Pattern = o.GetPattern()
+	}
+	if o.GetMaxLength() > 0 {
+		schema.MaxLength = intPtr(o.GetMaxLength())
+	}
+	if o.GetMinLength() > 0 {
+		schema.MinLength = intPtr(o.GetMinLength())
+	}
+	if o.GetMaxItems() > 0 {
+		schema.MaxItems = intPtr(o.GetMaxItems())
+	}
+	if o.GetMinItems() > 0 {
+		schema.MinItems = intPtr(o.GetMinItems())
+	}
+	if o.GetMaximum() != 0 {
+		v := o.GetMaximum()
+		schema.Maximum = &v
+	}
+	if o.GetMinimum() != 0 {
+		v := o.GetMinimum()
+		schema.Minimum = &v
+	}
+	if len(o.GetRequired()) > 0 {
+		schema.Required = o.GetRequired()
+	}
+	if yaml := o.GetExample().GetYaml(); yaml != "" {
+		schema.Example = yaml
+	}
+	schema.Nullable = schema.Nullable || o.GetNullable()
+	schema.ReadOnly = schema.ReadOnly || o.GetReadOnly()
+	schema.WriteOnly = schema.WriteOnly || o.GetWriteOnly()
+	schema.Deprecated = schema.Deprecated || o.GetDeprecated()
+}
+
+// httpPattern 返回 rule 的 HTTP 方法与路径模板
+func httpPattern(rule *annotations.HttpRule) (string, string) {
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return "GET", p.Get
+	case *annotations.HttpRule_Put:
+		return "PUT", p.Put
+	case *annotations.HttpRule_Post:
+		return "POST", p.Post
+	case *annotations.HttpRule_Delete:
+		return "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		return "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		return strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	}
+	return "", ""
+}
+
+// findField 按 proto 字段名路径查找字段，如 user.id
+func findField(msg protoreflect.MessageDescriptor, path string) protoreflect.FieldDescriptor {
+	var fd protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		if msg == nil {
+			return nil
+		}
+		if fd = msg.Fields().ByName(protoreflect.Name(name)); fd == nil {
+			return nil
+		}
+		msg = fd.Message()
+	}
+	return fd
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Content:  map[string]MediaType{"application/json": {Schema: schema}},
+		Required: true,
+	}
+}
+
+func intPtr(v int64) *int {
+	i := int(v)
+	return &i
+}
@@ -0,0 +1,103 @@
+package knife4g
+
+import (
+	"fmt"
+	"{{cookiecutter.project_name}}/pkg/i18n"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ErrorReason 错误原因及其对应的 HTTP 状态码
+type ErrorReason struct {
+	Reason  string
+	Code    int
+	Message string
+}
+
+var (
+	reasonsOnce sync.Once
+	reasons     map[string]ErrorReason
+)
+
+// errorReasons 从已注册的 proto 描述中收集声明了 errors.default_code 的错误原因
+func errorReasons() map[string]ErrorReason {
+	reasonsOnce.Do(func() {
+		reasons = make(map[string]ErrorReason)
+		protoregistry.GlobalFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+			enums := fd.Enums()
+			for i := 0; i < enums.Len(); i++ {
+				collectEnumReasons(enums.Get(i))
+			}
+			return true
+		})
+	})
+	return reasons
+}
+
+func collectEnumReasons(ed protoreflect.EnumDescriptor) {
+	opts := ed.Options()
+	if opts == nil || !proto.HasExtension(opts, errors.E_DefaultCode) {
+		return
+	}
+	defaultCode := proto.GetExtension(opts, errors.E_DefaultCode).(int32)
+	values := ed.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		code := defaultCode
+		if vo := v.Options(); vo != nil && proto.HasExtension(vo, errors.E_Code) {
+			code = proto.GetExtension(vo, errors.E_Code).(int32)
+		}
+		reason := string(v.Name())
+		msg, _ := i18n.ReasonMessage(i18n.Default, reason)
+		reasons[reason] = ErrorReason{Reason: reason, Code: int(code), Message: msg}
+	}
+}
+
+// errorResponses 按 HTTP 状态码汇总操作可能返回的错误原因
+func errorResponses(names []string) map[string]interface{} {
+	all := errorReasons()
+	grouped := make(map[int][]string)
+	for _, name := range names {
+		r, ok := all[name]
+		if !ok {
+			continue
+		}
+		line := r.Reason
+		if r.Message != "" {
+			line = fmt.Sprintf("%s: %s", r.Reason, r.Message)
+		}
+		grouped[r.Code] = append(grouped[r.Code], line)
+	}
+
+	responses := make(map[string]interface{}, len(grouped))
+	for code, lines := range grouped {
+		sort.Strings(lines)
+		responses[strconv.Itoa(code)] = map[string]interface{}{
+			"description": strings.Join(lines, "\n"),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": errorBodySchema,
+				},
+			},
+		}
+	}
+	return responses
+}
+
+// errorBodySchema 统一响应结构中的错误响应
+var errorBodySchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"code":    map[string]interface{}{"type": "integer", "description": "响应码"},
+		"msg":     map[string]interface{}{"type": "string", "description": "错误描述"},
+		"data":    map[string]interface{}{"type": "object"},
+		"traceID": map[string]interface{}{"type": "string"},
+	},
+}
@@ -349,6 +349,11 @@ func convertOperationToOpenAPI3(op *Operation) map[string]interface{} {
 		result["description"] = parser.GetString("description")
 	}
 
+	// 处理路径与查询参数
+	if len(op.Parameters) > 0 {
+		result["parameters"] = convertParametersToOpenAPI3(op.Parameters)
+	}
+
 	// 处理请求体
 	if op.RequestBody != nil {
 		requestBody := make(map[string]interface{})
@@ -367,6 +372,14 @@ func convertOperationToOpenAPI3(op *Operation) map[string]interface{} {
 		}
 		responses[code] = responseMap
 	}
+	// 处理 @errors 声明的错误原因
+	if parser.HasTag("errors") {
+		for code, response := range errorResponses(parser.GetArray("errors")) {
+			if _, ok := responses[code]; !ok {
+				responses[code] = response
+			}
+		}
+	}
 	result["responses"] = responses
 
 	return result
@@ -392,7 +405,9 @@ func convertContentToOpenAPI3(content map[string]MediaType) map[string]interface
 func convertSchemasToOpenAPI3(schemas map[string]Schema) map[string]interface{} {
 	result := make(map[string]interface{})
 	for name, schema := range schemas {
-		result[name] = convertSchemaToOpenAPI3(&schema)
+		converted := convertSchemaToOpenAPI3(&schema)
+		applyValidateRules(name, converted)
+		result[name] = converted
 	}
 	return result
 }
@@ -492,6 +507,10 @@ func convertSchemaToOpenAPI3(schema *Schema) map[string]interface{} {
 		result["properties"] = properties
 	}
 
+	if schema.Items != nil {
+		result["items"] = convertSchemaToOpenAPI3(schema.Items)
+	}
+
 	// 处理引用
 	if schema.Ref != "" {
 		result["$ref"] = schema.Ref
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	"{{cookiecutter.project_name}}/docs"
+
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,7 +16,11 @@ func LoadOpenAPIFromFile(filepath string) (*OpenAPI3, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read openapi file: %w", err)
 	}
+	return LoadOpenAPI(data)
+}
 
+// LoadOpenAPI 解析 YAML 或 JSON 格式的 OpenAPI 配置
+func LoadOpenAPI(data []byte) (*OpenAPI3, error) {
 	var openapi OpenAPI3
 	if err := yaml.Unmarshal(data, &openapi); err != nil {
 		return nil, fmt.Errorf("failed to parse openapi yaml: %w", err)
@@ -23,10 +30,17 @@ func LoadOpenAPIFromFile(filepath string) (*OpenAPI3, error) {
 }
 
 // NewDefaultConfig 创建默认的 Knife4g 配置
-// 默认使用 docs/api/openapi.yaml 作为 OpenAPI 配置文件
+// 优先使用编译时嵌入的 docs/api/openapi.yaml，未生成时根据已注册的 proto 描述生成，不依赖工作目录
 func NewDefaultConfig(serverName string) (*Config, error) {
-	openapiPath := "docs/api/openapi.yaml"
-	openapi, err := LoadOpenAPIFromFile(openapiPath)
+	var (
+		openapi *OpenAPI3
+		err     error
+	)
+	if data := docs.OpenAPI(); len(data) > 0 {
+		openapi, err = LoadOpenAPI(data)
+	} else {
+		openapi, err = LoadOpenAPIFromDescriptors(protoregistry.GlobalFiles)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,72 @@
+package knife4g
+
+import "strings"
+
+// sharedParameter api/v1/common 中公共查询参数的统一展示
+type sharedParameter struct {
+	description string
+	example     string
+	schema      map[string]interface{}
+}
+
+// sharedParameters 键为参数名去掉消息前缀后的部分，如 page.pageSize 对应 pageSize
+var sharedParameters = map[string]sharedParameter{
+	"pageSize": {
+		description: "每页数量，默认 20，最大 100",
+		example:     "20",
+		schema:      map[string]interface{}{"type": "integer", "format": "int32", "minimum": 0, "maximum": 100, "default": 20},
+	},
+	"pageToken": {
+		description: "上一页返回的 nextPageToken，为空时从第一页开始",
+		schema:      map[string]interface{}{"type": "string"},
+	},
+	"orderBy": {
+		description: "排序字段，逗号分隔，字段后加 desc 表示降序",
+		example:     "id desc",
+		schema:      map[string]interface{}{"type": "string"},
+	},
+	"filter": {
+		description: "过滤表达式，支持 = != > >= < <= : (包含) 及 AND OR NOT 与括号",
+		example:     `age >= 18 AND username:"k"`,
+		schema:      map[string]interface{}{"type": "string"},
+	},
+}
+
+// convertParametersToOpenAPI3 转换路径与查询参数，公共查询参数使用统一的说明与约束
+func convertParametersToOpenAPI3(params []Parameter) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(params))
+	for _, p := range params {
+		param := map[string]interface{}{
+			"name":     p.Name,
+			"in":       p.In,
+			"required": p.Required || p.In == "path",
+		}
+		if p.Description != "" {
+			param["description"] = p.Description
+		}
+		if p.Schema != nil {
+			param["schema"] = convertSchemaToOpenAPI3(p.Schema)
+		}
+		if p.Example != nil {
+			param["example"] = p.Example
+		}
+
+		name := p.Name[strings.LastIndexByte(p.Name, '.')+1:]
+		if shared, ok := sharedParameters[name]; ok && p.In == "query" {
+			param["description"] = shared.description
+			param["schema"] = shared.schema
+			if shared.example != "" {
+				param["example"] = shared.example
+			}
+		} else if p.Schema != nil && p.Schema.Format == "field-mask" {
+			// FieldMask 以逗号分隔的字段名传递
+			param["style"] = "form"
+			param["explode"] = false
+			if p.Description == "" {
+				param["description"] = "逗号分隔的字段名"
+			}
+		}
+		result = append(result, param)
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package knife4g
+
+import (
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// applyValidateRules 将 protoc-gen-validate 规则合并到 schema 的字段约束中
+// name 为 schema 名称，即消息的完整名称，如 v1.helloworld.User；未注册的消息不做处理
+func applyValidateRules(name string, schema map[string]interface{}) {
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	var required []string
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		rules, ok := proto.GetExtension(fd.Options(), validate.E_Rules).(*validate.FieldRules)
+		if !ok || rules == nil {
+			continue
+		}
+		if rules.GetMessage().GetRequired() {
+			required = append(required, fd.JSONName())
+		}
+		prop, ok := properties[fd.JSONName()].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if repeated := rules.GetRepeated(); repeated != nil {
+			if repeated.MinItems != nil {
+				prop["minItems"] = repeated.GetMinItems()
+			}
+			if repeated.MaxItems != nil {
+				prop["maxItems"] = repeated.GetMaxItems()
+			}
+			if repeated.GetUnique() {
+				prop["uniqueItems"] = true
+			}
+			if items, ok := prop["items"].(map[string]interface{}); ok && repeated.GetItems() != nil {
+				applyFieldRules(repeated.GetItems(), items)
+			}
+			continue
+		}
+		applyFieldRules(rules, prop)
+	}
+	if len(required) > 0 {
+		existing, _ := schema["required"].([]string)
+		schema["required"] = append(existing, required...)
+	}
+}
+
+// applyFieldRules 设置标量字段的约束
+func applyFieldRules(rules *validate.FieldRules, prop map[string]interface{}) {
+	if s := rules.GetString_(); s != nil {
+		if s.MinLen != nil {
+			prop["minLength"] = s.GetMinLen()
+		}
+		if s.MaxLen != nil {
+			prop["maxLength"] = s.GetMaxLen()
+		}
+		if s.Pattern != nil {
+			prop["pattern"] = s.GetPattern()
+		}
+		switch {
+		case s.GetEmail():
+			prop["format"] = "email"
+		case s.GetUuid():
+			prop["format"] = "uuid"
+		case s.GetUri():
+			prop["format"] = "uri"
+		case s.GetIpv4():
+			prop["format"] = "ipv4"
+		case s.GetIpv6():
+			prop["format"] = "ipv6"
+		case s.GetHostname():
+			prop["format"] = "hostname"
+		}
+		if len(s.GetIn()) > 0 {
+			enum := make([]interface{}, len(s.GetIn()))
+			for i, v := range s.GetIn() {
+				enum[i] = v
+			}
+			prop["enum"] = enum
+		}
+		return
+	}
+
+	// 数值类型的规则消息均包含 gt/gte/lt/lte/in 字段
+	m := rules.ProtoReflect()
+	oneof := m.Descriptor().Oneofs().ByName("type")
+	fd := m.WhichOneof(oneof)
+	if fd == nil || fd.Message() == nil {
+		return
+	}
+	applyNumberRules(m.Get(fd).Message(), prop)
+}
+
+func applyNumberRules(m protoreflect.Message, prop map[string]interface{}) {
+	if v, ok := number(m, "gte"); ok {
+		prop["minimum"] = v
+	} else if v, ok := number(m, "gt"); ok {
+		prop["minimum"] = v
+		prop["exclusiveMinimum"] = true
+	}
+	if v, ok := number(m, "lte"); ok {
+		prop["maximum"] = v
+	} else if v, ok := number(m, "lt"); ok {
+		prop["maximum"] = v
+		prop["exclusiveMaximum"] = true
+	}
+	fd := m.Descriptor().Fields().ByName("in")
+	if fd == nil || !fd.IsList() || m.Get(fd).List().Len() == 0 {
+		return
+	}
+	list := m.Get(fd).List()
+	enum := make([]interface{}, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if v, ok := toNumber(fd.Kind(), list.Get(i)); ok {
+			enum = append(enum, v)
+		}
+	}
+	prop["enum"] = enum
+}
+
+// number 读取数值规则中的字段，未设置或非数值类型时返回 false
+func number(m protoreflect.Message, name protoreflect.Name) (float64, bool) {
+	fd := m.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.IsList() || !m.Has(fd) {
+		return 0, false
+	}
+	return toNumber(fd.Kind(), m.Get(fd))
+}
+
+func toNumber(kind protoreflect.Kind, v protoreflect.Value) (float64, bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return float64(v.Int()), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return float64(v.Uint()), true
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float(), true
+	}
+	return 0, false
+}
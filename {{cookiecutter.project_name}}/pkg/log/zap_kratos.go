@@ -13,8 +13,25 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// level 全局日志级别，可在运行时通过管理端口调整
+var level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+
+// Level 返回全局日志级别
+func Level() zap.AtomicLevel {
+	return level
+}
+
 func New(cfg *conf.Zap, g *conf.Global) (log.Logger, error) {
 
+	// 未配置 log.level 时为 debug
+	if cfg.GetLevel() != "" {
+		l, err := zapcore.ParseLevel(cfg.GetLevel())
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", cfg.GetLevel(), err)
+		}
+		level.SetLevel(l)
+	}
+
 	writer := NewLoggerWriter(fmt.Sprintf("%s/%s-%s.log", cfg.Filename, g.AppName, time.Now().Format("20060102")), //文件名
 		int(cfg.MaxSize),
 		int(cfg.MaxBackups),
@@ -39,7 +56,7 @@ func newLogger(g *conf.Global, w *lumberjack.Logger) (log.Logger, error) {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	logger := NewZapLogger(encoder, zapcore.AddSync(w), zapcore.DebugLevel,
+	logger := NewZapLogger(encoder, zapcore.AddSync(w), level,
 		zap.AddStacktrace(zap.NewAtomicLevelAt(zapcore.ErrorLevel)),
 		zap.AddCaller(),
 		zap.AddCallerSkip(2),
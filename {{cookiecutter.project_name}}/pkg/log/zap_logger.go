@@ -8,7 +8,7 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-func NewZapLogger(encoder zapcore.EncoderConfig, logWrite zapcore.WriteSyncer, level zapcore.Level, opts ...zap.Option) *zap.Logger {
+func NewZapLogger(encoder zapcore.EncoderConfig, logWrite zapcore.WriteSyncer, level zapcore.LevelEnabler, opts ...zap.Option) *zap.Logger {
 
 	syncers := []zapcore.WriteSyncer{zapcore.AddSync(os.Stdout)}
 	if logWrite != nil {
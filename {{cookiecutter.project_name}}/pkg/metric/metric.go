@@ -49,4 +49,77 @@ var (
 			Help:      "Total number of Requests.",
 		}, []string{"name"}),
 	)
+
+	// 数据库操作耗时分布统计
+	DBDurationHistogram = NewRegisterHistogram(
+		prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_duration_seconds",
+			Help:      "database operation latencies in seconds.",
+		}, []string{"driver", "operation", "status"}),
+	)
+
+	// 慢查询数统计
+	DBSlowCount = NewRegisterCounter(
+		prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_slow_total",
+			Help:      "Total number of slow database operations.",
+		}, []string{"driver", "operation"}),
+	)
+
+	// 数据库连接池状态
+	DBConnectionsGauge = NewRegisterGauge(
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "db_connections",
+			Help:      "database connection pool stats.",
+		}, []string{"driver", "state"}),
+	)
+
+	// 缓存命中统计，result 取值 hit/miss/negative_hit/error
+	CacheCount = NewRegisterCounter(
+		prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_total",
+			Help:      "Total number of cache lookups.",
+		}, []string{"name", "result"}),
+	)
+
+	// 限流拒绝的请求数统计
+	RateLimitRejectedCount = NewRegisterCounter(
+		prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ratelimit_rejected_total",
+			Help:      "Total number of requests rejected by rate limit.",
+		}, []string{"rule", "operation"}),
+	)
+
+	// 过载保护丢弃的请求数统计
+	OverloadDroppedCount = NewRegisterCounter(
+		prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "overload_dropped_total",
+			Help:      "Total number of requests dropped by overload protection.",
+		}, []string{"service", "operation"}),
+	)
+
+	// 熔断器拒绝的下游请求数统计
+	BreakerRejectedCount = NewRegisterCounter(
+		prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "breaker_rejected_total",
+			Help:      "Total number of client requests rejected by circuit breaker.",
+		}, []string{"service", "operation"}),
+	)
+
+	// 压测请求耗时分位数，run 为压测编号，压测结束后删除
+	PerfDurationSummary = NewRegisterSummary(
+		prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Namespace:  namespace,
+			Name:       "perf_duration_seconds",
+			Help:       "load test request latencies in seconds.",
+			Objectives: map[float64]float64{0.5: 0.005, 0.9: 0.001, 0.95: 0.001, 0.99: 0.0001},
+		}, []string{"run", "path"}),
+	)
 )
@@ -0,0 +1,58 @@
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var _ Summary = (*summary)(nil)
+
+type summary struct {
+	sv  *prometheus.SummaryVec
+	lvs []string
+}
+
+// Summary is metrics summary.
+type Summary interface {
+	With(lvs ...string) Summary
+	Observe(float64)
+	// Quantiles 返回当前标签值的分位数、样本数与样本总和，分位数的 key 为 SummaryOpts.Objectives 中的分位
+	Quantiles() (map[float64]float64, uint64, float64)
+	// Delete 删除当前标签值的统计
+	Delete()
+}
+
+// NewRegisterSummary new a prometheus summary and returns Summary.
+func NewRegisterSummary(sv *prometheus.SummaryVec) Summary {
+	prometheus.MustRegister(sv)
+	return &summary{
+		sv: sv,
+	}
+}
+
+func (s *summary) With(lvs ...string) Summary {
+	return &summary{
+		sv:  s.sv,
+		lvs: lvs,
+	}
+}
+
+func (s *summary) Observe(value float64) {
+	s.sv.WithLabelValues(s.lvs...).Observe(value)
+}
+
+func (s *summary) Quantiles() (map[float64]float64, uint64, float64) {
+	var m dto.Metric
+	if err := s.sv.WithLabelValues(s.lvs...).(prometheus.Metric).Write(&m); err != nil {
+		return nil, 0, 0
+	}
+	q := make(map[float64]float64, len(m.GetSummary().GetQuantile()))
+	for _, v := range m.GetSummary().GetQuantile() {
+		q[v.GetQuantile()] = v.GetValue()
+	}
+	return q, m.GetSummary().GetSampleCount(), m.GetSummary().GetSampleSum()
+}
+
+func (s *summary) Delete() {
+	s.sv.DeleteLabelValues(s.lvs...)
+}
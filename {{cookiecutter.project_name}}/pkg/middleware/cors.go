@@ -1,76 +1,271 @@
 package middleware
 
 import (
-	"context"
 	"{{cookiecutter.project_name}}/configs/conf"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"github.com/go-kratos/kratos/v2/middleware"
-	"github.com/go-kratos/kratos/v2/transport"
 	kratoshttp "github.com/go-kratos/kratos/v2/transport/http"
 )
 
-func Cors(cors *conf.Server_Cors) middleware.Middleware {
-	return func(handler middleware.Handler) middleware.Handler {
-		return func(ctx context.Context, req interface{}) (interface{}, error) {
-			// 获取 HTTP transport 信息
-			if tr, ok := transport.FromServerContext(ctx); ok {
-				if ht, ok := tr.(*kratoshttp.Transport); ok {
-					httpReq := ht.Request()
-					httpResp := ht.Response()
-
-					origin := httpReq.Header.Get("Origin")
-
-					if cors.Mode == "allow-all" || origin == "" || origin == "null" {
-						// 允许所有来源的配置
-						httpResp.Header().Set("Access-Control-Allow-Origin", "*")
-						httpResp.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-						httpResp.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, DELETE, OPTIONS")
-						httpResp.Header().Set("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
-						httpResp.Header().Set("Access-Control-Allow-Credentials", "true")
-
-						// 处理预检请求
-						if httpReq.Method == http.MethodOptions {
-							httpResp.WriteHeader(http.StatusNoContent)
-							return nil, nil
-						}
-					} else {
-						// 白名单模式
-						whitelist := checkOrigin(origin, cors.Whitelist)
-						if whitelist != nil {
-							httpResp.Header().Set("Access-Control-Allow-Origin", whitelist.AllowOrigin)
-							httpResp.Header().Set("Access-Control-Allow-Headers", whitelist.AllowHeaders)
-							httpResp.Header().Set("Access-Control-Allow-Methods", whitelist.AllowMethods)
-							httpResp.Header().Set("Access-Control-Expose-Headers", whitelist.ExposeHeaders)
-							if whitelist.AllowCredentials {
-								httpResp.Header().Set("Access-Control-Allow-Credentials", "true")
-							}
-
-							// 处理预检请求
-							if httpReq.Method == http.MethodOptions {
-								httpResp.WriteHeader(http.StatusNoContent)
-								return nil, nil
-							}
-						} else {
-							// 不在白名单中，返回 403
-							httpResp.WriteHeader(http.StatusForbidden)
-							return nil, nil
-						}
-					}
+const (
+	CorsModeAllowAll  = "allow-all" // 允许所有来源
+	CorsModeWhitelist = "whitelist" // 仅允许白名单中的来源
+	CorsModeDisable   = "disable"   // 关闭跨域处理
+)
+
+var (
+	defaultAllowMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions}
+	defaultExposeHeader = "Content-Length, Content-Type"
+)
+
+// corsPolicy 预编译后的跨域策略
+type corsPolicy struct {
+	anyOrigin        bool
+	origins          []originPattern
+	allowMethods     []string
+	allowHeaders     []string // 为空时回显请求中的 Access-Control-Request-Headers
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           int32
+}
+
+// originPattern 来源匹配规则，支持 "https://*.example.com" 与 "*.example.com" 形式的通配
+type originPattern struct {
+	prefix   string
+	suffix   string
+	wildcard bool
+	hostOnly bool // 规则中未包含协议时仅比较 host 部分
+}
+
+// Cors 返回 net/http 层的跨域过滤器
+// 过滤器在路由匹配之前执行，因此对任意路径的 OPTIONS 预检请求都能生效
+func Cors(c *conf.Server_Cors) kratoshttp.FilterFunc {
+	policies := buildCorsPolicies(c)
+	return func(next http.Handler) http.Handler {
+		if len(policies) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				// 非跨域请求
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if isSameOrigin(origin, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p := matchCorsPolicy(origin, policies)
+			if p == nil {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				// 不在白名单中：不返回跨域头，由浏览器拦截响应
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if preflight {
+				handlePreflight(w, r, p, origin)
+				return
+			}
+
+			setAllowOrigin(h, p, origin)
+			if p.exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", p.exposeHeaders)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// handlePreflight 处理 OPTIONS 预检请求
+func handlePreflight(w http.ResponseWriter, r *http.Request, p *corsPolicy, origin string) {
+	h := w.Header()
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if !containsFold(p.allowMethods, method) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	reqHeaders := r.Header.Get("Access-Control-Request-Headers")
+	if len(p.allowHeaders) > 0 {
+		for _, v := range splitList(reqHeaders) {
+			if !containsFold(p.allowHeaders, v) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+		// 携带凭证时浏览器将 "*" 视为普通头名，需回显请求的头
+		if p.allowCredentials && containsFold(p.allowHeaders, "*") {
+			if reqHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", reqHeaders)
+			}
+		} else {
+			h.Set("Access-Control-Allow-Headers", strings.Join(p.allowHeaders, ", "))
+		}
+	} else if reqHeaders != "" {
+		h.Set("Access-Control-Allow-Headers", reqHeaders)
+	}
+
+	setAllowOrigin(h, p, origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(p.allowMethods, ", "))
+	if p.maxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.maxAge)))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setAllowOrigin 设置允许的来源
+// 携带凭证时浏览器不接受 "*"，需回显具体的 Origin
+func setAllowOrigin(h http.Header, p *corsPolicy, origin string) {
+	if p.anyOrigin && !p.allowCredentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func buildCorsPolicies(c *conf.Server_Cors) []*corsPolicy {
+	if c == nil {
+		return nil
+	}
+	switch c.Mode {
+	case CorsModeAllowAll:
+		return []*corsPolicy{{
+			anyOrigin:        true,
+			allowMethods:     defaultAllowMethods,
+			exposeHeaders:    defaultExposeHeader,
+			allowCredentials: c.AllowCredentials,
+			maxAge:           c.MaxAge,
+		}}
+	case CorsModeWhitelist:
+		policies := make([]*corsPolicy, 0, len(c.Whitelist))
+		for _, v := range c.Whitelist {
+			p := &corsPolicy{
+				allowMethods:     defaultAllowMethods,
+				allowHeaders:     splitList(v.AllowHeaders),
+				exposeHeaders:    v.ExposeHeaders,
+				allowCredentials: v.AllowCredentials,
+				maxAge:           v.MaxAge,
+			}
+			if methods := splitList(strings.ToUpper(v.AllowMethods)); len(methods) > 0 {
+				p.allowMethods = methods
+			}
+			for _, o := range splitList(v.AllowOrigin) {
+				if o == "*" {
+					p.anyOrigin = true
+					continue
 				}
+				p.origins = append(p.origins, newOriginPattern(o))
+			}
+			if p.anyOrigin || len(p.origins) > 0 {
+				policies = append(policies, p)
 			}
+		}
+		return policies
+	default:
+		return nil
+	}
+}
+
+func newOriginPattern(o string) originPattern {
+	o = strings.ToLower(strings.TrimSuffix(o, "/"))
+	p := originPattern{hostOnly: !strings.Contains(o, "://")}
+	if i := strings.IndexByte(o, '*'); i >= 0 {
+		p.wildcard = true
+		p.prefix, p.suffix = o[:i], o[i+1:]
+	} else {
+		p.prefix = o
+	}
+	return p
+}
 
-			// 继续执行后续处理器
-			return handler(ctx, req)
+func (p originPattern) match(origin string) bool {
+	if p.hostOnly {
+		if i := strings.Index(origin, "://"); i >= 0 {
+			origin = origin[i+3:]
 		}
 	}
+	if !p.wildcard {
+		return origin == p.prefix
+	}
+	if len(origin) <= len(p.prefix)+len(p.suffix) ||
+		!strings.HasPrefix(origin, p.prefix) || !strings.HasSuffix(origin, p.suffix) {
+		return false
+	}
+	// 通配部分只能匹配子域名，不能跨越协议、端口或路径
+	sub := origin[len(p.prefix) : len(origin)-len(p.suffix)]
+	return !strings.ContainsAny(sub, "/:")
 }
 
-func checkOrigin(origin string, whitelist []*conf.Server_Cors_Whitelist) *conf.Server_Cors_Whitelist {
-	for _, v := range whitelist {
-		if origin == v.AllowOrigin {
-			return v
+func matchCorsPolicy(origin string, policies []*corsPolicy) *corsPolicy {
+	origin = strings.ToLower(origin)
+	for _, p := range policies {
+		if p.anyOrigin {
+			return p
+		}
+		for _, o := range p.origins {
+			if o.match(origin) {
+				return p
+			}
 		}
 	}
 	return nil
 }
+
+// isSameOrigin 判断是否为同源请求，同源请求无需跨域处理
+// 协议与 host 均一致才视为同源，经反向代理时协议取自 X-Forwarded-Proto
+func isSameOrigin(origin string, r *http.Request) bool {
+	i := strings.Index(origin, "://")
+	if i < 0 {
+		return false
+	}
+	return strings.EqualFold(origin[:i], requestScheme(r)) && strings.EqualFold(origin[i+3:], r.Host)
+}
+
+func requestScheme(r *http.Request) string {
+	if proto := splitList(r.Header.Get("X-Forwarded-Proto")); len(proto) > 0 {
+		return proto[0]
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if s == "*" || strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
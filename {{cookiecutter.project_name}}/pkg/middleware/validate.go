@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// ValidateReason 请求参数校验失败的错误原因，响应码为 response.ParamsFailed
+const ValidateReason = "VALIDATOR"
+
+func init() {
+	response.RegisterReason(ValidateReason, response.ParamsFailed)
+}
+
+// validatorAll protoc-gen-validate 生成的 ValidateAll，返回全部字段错误
+type validatorAll interface {
+	ValidateAll() error
+}
+
+// validator protoc-gen-validate 生成的 Validate，遇到第一个错误即返回
+type validator interface {
+	Validate() error
+}
+
+// fieldError protoc-gen-validate 生成的字段错误
+type fieldError interface {
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiError protoc-gen-validate 生成的 XxxMultiError
+type multiError interface {
+	AllErrors() []error
+}
+
+// Validator 使用 protoc-gen-validate 生成的规则校验请求
+// 校验失败时返回 400，metadata 中为字段路径到错误描述的映射
+func Validator() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var err error
+			switch v := req.(type) {
+			case validatorAll:
+				err = v.ValidateAll()
+			case validator:
+				err = v.Validate()
+			}
+			if err != nil {
+				return nil, kerrors.BadRequest(ValidateReason, "request validation failed").
+					WithMetadata(Violations(err)).WithCause(err)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Violations 将 protoc-gen-validate 的校验错误转换为字段路径到错误描述的映射
+func Violations(err error) map[string]string {
+	violations := make(map[string]string)
+	collectViolations("", err, violations)
+	return violations
+}
+
+// collectViolations 展开嵌套消息的校验错误，字段路径使用 json 字段名，如 user.hobby[0]
+func collectViolations(prefix string, err error, violations map[string]string) {
+	var multi multiError
+	if errors.As(err, &multi) {
+		for _, e := range multi.AllErrors() {
+			collectViolations(prefix, e, violations)
+		}
+		return
+	}
+	var fe fieldError
+	if !errors.As(err, &fe) {
+		violations[strings.TrimSuffix(prefix, ".")] = err.Error()
+		return
+	}
+	path := prefix + jsonName(fe.Field())
+	if cause := fe.Cause(); cause != nil {
+		var nested fieldError
+		if errors.As(cause, &nested) || errors.As(cause, &multi) {
+			collectViolations(path+".", cause, violations)
+			return
+		}
+	}
+	violations[path] = fe.Reason()
+}
+
+// jsonName 将生成代码中的 UpperCamelCase 字段名转换为 json 字段名
+func jsonName(field string) string {
+	r, size := utf8.DecodeRuneInString(field)
+	return string(unicode.ToLower(r)) + field[size:]
+}
@@ -0,0 +1,413 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"{{cookiecutter.project_name}}/pkg/database"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	// Table 记录已执行迁移的表
+	Table = "schema_migrations"
+
+	defaultLockTimeout = time.Minute
+	// mysqlLockName MySQL 迁移锁的名称，GET_LOCK 作用于整个实例，以库名区分共用实例的应用；名称最长 64 个字符
+	mysqlLockName = "LEFT(CONCAT(IFNULL(DATABASE(), ''), '.', ?), 64)"
+)
+
+var (
+	// fileRegexp 迁移文件名格式：<版本>_<名称>.up.sql / <版本>_<名称>.down.sql
+	fileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	nameRegexp = regexp.MustCompile(`^\w+$`)
+)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status 迁移执行状态
+type Status struct {
+	Migration
+	AppliedAt *time.Time // 为 nil 表示未执行
+}
+
+// Migrator 执行版本化的 SQL 迁移
+type Migrator struct {
+	db          *database.DB
+	migrations  []Migration
+	lockTimeout time.Duration
+	log         *log.Helper
+}
+
+// Option 迁移选项
+type Option func(*Migrator)
+
+// WithLockTimeout 设置等待迁移锁的超时时间，默认 1 分钟
+func WithLockTimeout(d time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = d
+	}
+}
+
+// New 创建 Migrator，fsys 的根目录下按方言划分子目录，如 mysql/0001_init.up.sql
+func New(db *database.DB, fsys fs.FS, logger log.Logger, opts ...Option) (*Migrator, error) {
+	sub, err := fs.Sub(fsys, db.Dialect().String())
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		return nil, err
+	}
+	m := &Migrator{
+		db:          db,
+		migrations:  migrations,
+		lockTimeout: defaultLockTimeout,
+		log:         log.NewHelper(log.With(logger, "module", "migrate")),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// Load 读取目录下的迁移文件，按版本升序返回
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		match := fileRegexp.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up 执行全部未执行的迁移
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, mg := range m.migrations {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+			if err := m.run(ctx, mg, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(ctx context.Context) error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mg := m.migrations[i]
+			if _, ok := applied[mg.Version]; !ok {
+				continue
+			}
+			if mg.Down == "" {
+				return fmt.Errorf("migration %04d_%s has no down file", mg.Version, mg.Name)
+			}
+			if err := m.run(ctx, mg, false); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// Status 返回全部迁移及其执行时间
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Status, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		s := Status{Migration: mg}
+		if t, ok := applied[mg.Version]; ok {
+			s.AppliedAt = &t
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// run 在事务中执行一个迁移并更新版本记录
+// MySQL 的 DDL 会隐式提交，失败时需根据日志手动处理
+func (m *Migrator) run(ctx context.Context, mg Migration, up bool) error {
+	direction, script := "up", mg.Up
+	if !up {
+		direction, script = "down", mg.Down
+	}
+	start := time.Now()
+	err := m.db.InTx(ctx, func(ctx context.Context) error {
+		for _, stmt := range splitStatements(script) {
+			if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		var err error
+		if up {
+			_, err = m.db.ExecContext(ctx, "INSERT INTO "+Table+" (version, name, applied_at) VALUES (?, ?, ?)",
+				mg.Version, mg.Name, time.Now().UTC())
+		} else {
+			_, err = m.db.ExecContext(ctx, "DELETE FROM "+Table+" WHERE version = ?", mg.Version)
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s %s failed: %w", mg.Version, mg.Name, direction, err)
+	}
+	m.log.WithContext(ctx).Infof("migration %04d_%s %s done in %s", mg.Version, mg.Name, direction, time.Since(start))
+	return nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+Table+
+		" (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)")
+	return err
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM "+Table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// withLock 获取迁移锁后执行 fn，保证多个实例同时启动时只有一个执行迁移
+// MySQL 使用以库名区分的 GET_LOCK，Postgres 使用 advisory lock（作用于当前库）；SQLite 见 withSQLiteLock
+func (m *Migrator) withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.db.Dialect() == database.SQLite {
+		return m.withSQLiteLock(ctx, fn)
+	}
+	lockCtx, cancel := context.WithTimeout(ctx, m.lockTimeout)
+	defer cancel()
+
+	var unlock func()
+	switch m.db.Dialect() {
+	case database.MySQL:
+		conn, err := m.db.Conn(lockCtx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		var ok sql.NullInt64
+		err = conn.QueryRowContext(lockCtx, "SELECT GET_LOCK("+mysqlLockName+", ?)", Table, int(m.lockTimeout.Seconds())).Scan(&ok)
+		if err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if ok.Int64 != 1 {
+			return fmt.Errorf("failed to acquire migration lock: timeout after %s", m.lockTimeout)
+		}
+		unlock = func() { _, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK("+mysqlLockName+")", Table) }
+	case database.Postgres:
+		conn, err := m.db.Conn(lockCtx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", lockKey()); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		unlock = func() { _, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey()) }
+	default:
+		unlock = func() {}
+	}
+	defer unlock()
+
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+// withSQLiteLock 在一个事务中执行 fn，事务的第一条语句即写入迁移表，立即取得数据库写锁，等同于 BEGIN IMMEDIATE
+// 其他实例最多等待 lockTimeout，取得锁后读到已执行的迁移；任一迁移失败时本次执行的迁移全部回滚
+func (m *Migrator) withSQLiteLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	return m.db.InTx(ctx, func(ctx context.Context) error {
+		var busyTimeout int64
+		if err := m.db.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+			return err
+		}
+		if _, err := m.db.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", m.lockTimeout.Milliseconds())); err != nil {
+			return err
+		}
+		// 先读后写会在锁冲突时直接失败而不等待，因此第一条语句必须是写语句
+		_, err := m.db.ExecContext(ctx, "DELETE FROM "+Table+" WHERE version < 0")
+		_, _ = m.db.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", busyTimeout))
+		if err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		return fn(ctx)
+	})
+}
+
+// lockKey Postgres advisory lock 的键
+func lockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(Table))
+	return int64(h.Sum64())
+}
+
+// Create 在 dir 下每个方言子目录中创建下一个版本的空迁移文件，返回创建的文件路径
+func Create(dir, name string) ([]string, error) {
+	if !nameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q, only letters, digits and '_' are allowed", name)
+	}
+	dialects := []string{database.MySQL.String(), database.Postgres.String(), database.SQLite.String()}
+	var version int64
+	for _, d := range dialects {
+		migrations, err := Load(os.DirFS(filepath.Join(dir, d)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if n := len(migrations); n > 0 && migrations[n-1].Version > version {
+			version = migrations[n-1].Version
+		}
+	}
+	version++
+
+	files := make([]string, 0, len(dialects)*2)
+	for _, d := range dialects {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			return nil, err
+		}
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, d, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+			content := fmt.Sprintf("-- %s %s\n", name, direction)
+			if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// splitStatements 按分号拆分脚本中的语句，跳过引号与注释中的分号
+func splitStatements(script string) []string {
+	var (
+		stmts []string
+		b     strings.Builder
+		quote byte
+	)
+	flush := func() {
+		if s := strings.TrimSpace(b.String()); s != "" && !isComment(s) {
+			stmts = append(stmts, s)
+		}
+		b.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			b.WriteString(script[i : i+end])
+			i += end - 1
+			continue
+		case c == ';':
+			flush()
+			continue
+		}
+		b.WriteByte(c)
+	}
+	flush()
+	return stmts
+}
+
+// isComment 判断语句是否只包含注释
+func isComment(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Event 数据变更事件
+type Event struct {
+	// Topic 事件主题，如 dict、settings
+	Topic string `json:"topic"`
+	// Key 变更的数据标识
+	Key string `json:"key"`
+	// Action create | update | delete
+	Action string `json:"action"`
+	// Version 变更后的版本号，删除时为 0
+	Version int64 `json:"version,omitempty"`
+}
+
+// 事件动作
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Notifier 发布变更事件并分发给订阅者
+type Notifier interface {
+	// Publish 发布事件，所有实例上订阅该主题的回调都会收到
+	Publish(ctx context.Context, e Event) error
+	// Subscribe 订阅主题，返回的函数用于取消订阅，回调不应阻塞
+	Subscribe(topic string, fn func(Event)) (cancel func())
+	Close() error
+}
+
+// Local 进程内的事件分发，仅通知本实例的订阅者
+type Local struct {
+	mu   sync.RWMutex
+	seq  int
+	subs map[string]map[int]func(Event)
+}
+
+var _ Notifier = (*Local)(nil)
+
+// NewLocal 创建进程内的事件分发
+func NewLocal() *Local {
+	return &Local{subs: make(map[string]map[int]func(Event))}
+}
+
+// Publish 实现 Notifier，同步调用订阅者
+func (l *Local) Publish(_ context.Context, e Event) error {
+	l.dispatch(e)
+	return nil
+}
+
+// Subscribe 实现 Notifier
+func (l *Local) Subscribe(topic string, fn func(Event)) func() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	id := l.seq
+	if l.subs[topic] == nil {
+		l.subs[topic] = make(map[int]func(Event))
+	}
+	l.subs[topic][id] = fn
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subs[topic], id)
+	}
+}
+
+// Close 实现 Notifier
+func (l *Local) Close() error {
+	return nil
+}
+
+func (l *Local) dispatch(e Event) {
+	l.mu.RLock()
+	fns := make([]func(Event), 0, len(l.subs[e.Topic]))
+	for _, fn := range l.subs[e.Topic] {
+		fns = append(fns, fn)
+	}
+	l.mu.RUnlock()
+	for _, fn := range fns {
+		fn(e)
+	}
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 通过 Redis 发布订阅在实例间分发事件
+// 本实例发布的事件直接分发给本地订阅者，不等待 Redis 回传
+type Redis struct {
+	*Local
+	rdb      redis.UniversalClient
+	channel  string
+	instance string
+	pubsub   *redis.PubSub
+}
+
+var _ Notifier = (*Redis)(nil)
+
+type message struct {
+	Instance string `json:"instance"`
+	Event
+}
+
+// NewRedis 订阅 channel，返回前确认订阅成功
+func NewRedis(ctx context.Context, rdb redis.UniversalClient, channel string) (*Redis, error) {
+	r := &Redis{
+		Local:    NewLocal(),
+		rdb:      rdb,
+		channel:  channel,
+		instance: fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano()),
+		pubsub:   rdb.Subscribe(ctx, channel),
+	}
+	if _, err := r.pubsub.Receive(ctx); err != nil {
+		_ = r.pubsub.Close()
+		return nil, fmt.Errorf("notify: subscribe %s: %w", channel, err)
+	}
+	go r.listen()
+	return r, nil
+}
+
+// Publish 实现 Notifier
+func (r *Redis) Publish(ctx context.Context, e Event) error {
+	r.dispatch(e)
+	data, err := json.Marshal(message{Instance: r.instance, Event: e})
+	if err != nil {
+		return err
+	}
+	return r.rdb.Publish(ctx, r.channel, data).Err()
+}
+
+// Close 实现 Notifier
+func (r *Redis) Close() error {
+	return r.pubsub.Close()
+}
+
+func (r *Redis) listen() {
+	for msg := range r.pubsub.Channel() {
+		var m message
+		if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil || m.Instance == r.instance {
+			continue
+		}
+		r.dispatch(m.Event)
+	}
+}
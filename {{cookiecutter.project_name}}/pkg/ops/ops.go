@@ -0,0 +1,234 @@
+// Package ops 提供指标、健康检查、日志级别、配置与注册中心状态等运维接口，应挂载在需要认证的管理端口上
+package ops
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"{{cookiecutter.project_name}}/pkg/http/response"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
+)
+
+// 运维接口的错误原因
+const (
+	// ReasonInvalidLogLevel 日志级别不合法，响应码为 response.ParamsFailed
+	ReasonInvalidLogLevel = "INVALID_LOG_LEVEL"
+	// ReasonRegistryFailed 查询注册中心失败，响应码为 response.InternalError
+	ReasonRegistryFailed = "REGISTRY_FAILED"
+)
+
+// defaultCheckTimeout 单个就绪检查的超时
+const defaultCheckTimeout = 3 * time.Second
+
+func init() {
+	response.RegisterReason(ReasonInvalidLogLevel, response.ParamsFailed)
+	response.RegisterReason(ReasonRegistryFailed, response.InternalError)
+}
+
+// Checks 就绪检查，key 为依赖名称，返回 error 表示依赖不可用
+type Checks map[string]func(context.Context) error
+
+// Option 选项
+type Option func(*Ops)
+
+// WithChecks /readyz 执行的就绪检查
+func WithChecks(checks Checks) Option {
+	return func(o *Ops) {
+		o.checks = checks
+	}
+}
+
+// WithLogLevel 可通过 /log/level 调整的日志级别
+func WithLogLevel(level zap.AtomicLevel) Option {
+	return func(o *Ops) {
+		o.level = &level
+	}
+}
+
+// WithConfig /config 返回的生效配置，sensitive 为额外需要隐藏的字段名
+func WithConfig(config proto.Message, sensitive ...string) Option {
+	return func(o *Ops) {
+		o.config = config
+		for _, name := range sensitive {
+			o.sensitive[strings.ToLower(name)] = struct{}{}
+		}
+	}
+}
+
+// WithRegistry /registry 查询的注册中心，endpoints 为本实例各服务的地址
+func WithRegistry(discovery registry.Discovery, service string, endpoints ...func() (*url.URL, error)) Option {
+	return func(o *Ops) {
+		o.discovery = discovery
+		o.service = service
+		o.endpoints = endpoints
+	}
+}
+
+// Ops 运维接口
+type Ops struct {
+	checks    Checks
+	level     *zap.AtomicLevel
+	config    proto.Message
+	sensitive map[string]struct{}
+	discovery registry.Discovery
+	service   string
+	endpoints []func() (*url.URL, error)
+}
+
+// Register 在 srv 上注册运维接口，未配置的选项对应的接口不注册
+//
+//	GET /metrics     Prometheus 指标
+//	GET /healthz     存活检查
+//	GET /readyz      就绪检查，任一依赖不可用时返回 503
+//	GET /log/level   当前日志级别
+//	PUT /log/level   调整日志级别，如 {"level":"warn"}
+//	GET /config      生效的配置，敏感字段已隐藏
+//	GET /registry    本实例在注册中心的状态
+func Register(srv *http.Server, opts ...Option) *Ops {
+	o := &Ops{sensitive: make(map[string]struct{}, len(defaultSensitive))}
+	for name := range defaultSensitive {
+		o.sensitive[name] = struct{}{}
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	srv.Handle("/metrics", promhttp.Handler())
+
+	r := srv.Route("/")
+	r.GET("/healthz", o.healthz)
+	r.GET("/readyz", o.readyz)
+	if o.level != nil {
+		r.GET("/log/level", o.getLevel)
+		r.PUT("/log/level", o.setLevel)
+	}
+	if o.config != nil {
+		r.GET("/config", o.getConfig)
+	}
+	r.GET("/registry", o.registry)
+	return o
+}
+
+// Health 健康检查结果，status 为 UP 或 DOWN
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// healthz 与 readyz 直接写出 JSON，便于探针与负载均衡器按状态码判断
+func (o *Ops) healthz(ctx http.Context) error {
+	return ctx.JSON(200, &Health{Status: "UP"})
+}
+
+func (o *Ops) readyz(ctx http.Context) error {
+	h := &Health{Status: "UP", Checks: make(map[string]string, len(o.checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range o.checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, defaultCheckTimeout)
+			defer cancel()
+			result := "UP"
+			if err := check(cctx); err != nil {
+				result = "DOWN: " + err.Error()
+			}
+			mu.Lock()
+			h.Checks[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	for _, result := range h.Checks {
+		if result != "UP" {
+			h.Status = "DOWN"
+			return ctx.JSON(503, h)
+		}
+	}
+	return ctx.JSON(200, h)
+}
+
+// LogLevel 日志级别：debug | info | warn | error | dpanic | panic | fatal
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+func (o *Ops) getLevel(ctx http.Context) error {
+	return ctx.Result(200, &LogLevel{Level: o.level.String()})
+}
+
+func (o *Ops) setLevel(ctx http.Context) error {
+	var in LogLevel
+	if err := ctx.Bind(&in); err != nil {
+		return errors.BadRequest(ReasonInvalidLogLevel, "invalid request").WithCause(err)
+	}
+	l, err := zapcore.ParseLevel(in.Level)
+	if err != nil || in.Level == "" {
+		return errors.BadRequest(ReasonInvalidLogLevel, fmt.Sprintf("unknown log level %q", in.Level))
+	}
+	o.level.SetLevel(l)
+	return ctx.Result(200, &LogLevel{Level: l.String()})
+}
+
+func (o *Ops) getConfig(ctx http.Context) error {
+	return ctx.Result(200, redact(o.config, o.sensitive))
+}
+
+// RegistryStatus 注册中心状态
+type RegistryStatus struct {
+	Enabled bool   `json:"enabled"`
+	Service string `json:"service,omitempty"`
+	// Endpoints 本实例的地址及是否已注册
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+	// Instances 注册中心中该服务的全部实例
+	Instances []*registry.ServiceInstance `json:"instances,omitempty"`
+}
+
+// EndpointStatus 本实例一个地址的注册状态
+type EndpointStatus struct {
+	Endpoint   string `json:"endpoint"`
+	Registered bool   `json:"registered"`
+}
+
+func (o *Ops) registry(ctx http.Context) error {
+	if o.discovery == nil {
+		return ctx.Result(200, &RegistryStatus{})
+	}
+	instances, err := o.discovery.GetService(ctx, o.service)
+	if err != nil {
+		return errors.InternalServer(ReasonRegistryFailed, "get service from registry").WithCause(err)
+	}
+	registered := make(map[string]struct{})
+	for _, ins := range instances {
+		for _, e := range ins.Endpoints {
+			registered[e] = struct{}{}
+		}
+	}
+	status := &RegistryStatus{Enabled: true, Service: o.service, Instances: instances}
+	for _, endpoint := range o.endpoints {
+		u, err := endpoint()
+		if err != nil {
+			continue
+		}
+		_, ok := registered[u.String()]
+		status.Endpoints = append(status.Endpoints, EndpointStatus{Endpoint: u.String(), Registered: ok})
+	}
+	sort.Slice(status.Endpoints, func(i, j int) bool {
+		return status.Endpoints[i].Endpoint < status.Endpoints[j].Endpoint
+	})
+	return ctx.Result(200, status)
+}
@@ -0,0 +1,59 @@
+package ops
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// redacted 敏感字段的替换值
+const redacted = "******"
+
+// defaultSensitive 默认隐藏的字段名，小写
+var defaultSensitive = map[string]struct{}{
+	"password":  {},
+	"secret":    {},
+	"token":     {},
+	"accesskey": {},
+	"secretkey": {},
+}
+
+// redact 返回 m 的副本，名称在 sensitive 中的非空字符串字段替换为 ******
+func redact(m proto.Message, sensitive map[string]struct{}) proto.Message {
+	c := proto.Clone(m)
+	redactMessage(c.ProtoReflect(), sensitive)
+	return c
+}
+
+func redactMessage(m protoreflect.Message, sensitive map[string]struct{}) {
+	// Range 中不修改消息，先记录再替换
+	var hidden []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redactMessage(mv.Message(), sensitive)
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				for i := 0; i < v.List().Len(); i++ {
+					redactMessage(v.List().Get(i).Message(), sensitive)
+				}
+			}
+		case fd.Message() != nil:
+			redactMessage(v.Message(), sensitive)
+		case fd.Kind() == protoreflect.StringKind:
+			if _, ok := sensitive[strings.ToLower(string(fd.Name()))]; ok && v.String() != "" {
+				hidden = append(hidden, fd)
+			}
+		}
+		return true
+	})
+	for _, fd := range hidden {
+		m.Set(fd, protoreflect.ValueOfString(redacted))
+	}
+}
@@ -0,0 +1,136 @@
+package overload
+
+import (
+	"context"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"{{cookiecutter.project_name}}/pkg/metric"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/aegis/ratelimit"
+	"github.com/go-kratos/aegis/ratelimit/bbr"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// ReasonOverloaded 服务过载，响应码为 response.RateLimitAllowFailed
+const ReasonOverloaded = "OVERLOADED"
+
+// ErrOverloaded 请求因服务过载被拒绝
+var ErrOverloaded = errors.ServiceUnavailable(ReasonOverloaded, "service is overloaded")
+
+func init() {
+	response.RegisterReason(ReasonOverloaded, response.RateLimitAllowFailed)
+}
+
+// Shedder 按服务分别统计的 BBR 过载保护
+type Shedder struct {
+	defaults []bbr.Option
+	services map[string][]bbr.Option
+
+	mu       sync.Mutex
+	limiters map[string]*bbr.BBR
+}
+
+// New 根据配置创建过载保护，CPU 使用率按 GOMAXPROCS（由 automaxprocs 按容器配额设置）折算
+func New(c *conf.Server_Overload) (*Shedder, error) {
+	defaults, err := policyOptions(c.GetPolicy(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s := &Shedder{defaults: defaults, services: make(map[string][]bbr.Option), limiters: make(map[string]*bbr.BBR)}
+	for name, p := range c.GetServices() {
+		if s.services[name], err = policyOptions(p, c.GetPolicy()); err != nil {
+			return nil, fmt.Errorf("%w (service %s)", err, name)
+		}
+	}
+	return s, nil
+}
+
+// Server 过载保护中间件，被拒绝的请求返回 503
+func Server(s *Shedder, logger log.Logger) middleware.Middleware {
+	helper := log.NewHelper(logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			operation := ""
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				operation = tr.Operation()
+			}
+			service := serviceName(operation)
+			limiter := s.limiter(service)
+			done, e := limiter.Allow()
+			if e != nil {
+				stat := limiter.Stat()
+				metric.OverloadDroppedCount.With(service, operation).Inc()
+				helper.WithContext(ctx).Warnf("overload: drop %s, cpu=%d inFlight=%d maxInFlight=%d",
+					operation, stat.CPU, stat.InFlight, stat.MaxInFlight)
+				return nil, ErrOverloaded
+			}
+			reply, err = handler(ctx, req)
+			done(ratelimit.DoneInfo{Err: err})
+			return reply, err
+		}
+	}
+}
+
+// limiter 返回服务对应的限流器，首次请求时创建
+func (s *Shedder) limiter(service string) *bbr.BBR {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.limiters[service]
+	if !ok {
+		opts, ok := s.services[service]
+		if !ok {
+			opts = s.defaults
+		}
+		l = bbr.NewLimiter(opts...)
+		s.limiters[service] = l
+	}
+	return l
+}
+
+// policyOptions 将策略转换为 bbr 选项，p 中未设置的字段使用 fallback
+func policyOptions(p, fallback *conf.Server_Overload_Policy) ([]bbr.Option, error) {
+	var opts []bbr.Option
+	if n := runtime.GOMAXPROCS(0); n < runtime.NumCPU() {
+		opts = append(opts, bbr.WithCPUQuota(float64(n)))
+	}
+	if v := p.GetCpuThreshold(); v > 0 {
+		opts = append(opts, bbr.WithCPUThreshold(v))
+	} else if v := fallback.GetCpuThreshold(); v > 0 {
+		opts = append(opts, bbr.WithCPUThreshold(v))
+	}
+	if v := p.GetBucket(); v > 0 {
+		opts = append(opts, bbr.WithBucket(int(v)))
+	} else if v := fallback.GetBucket(); v > 0 {
+		opts = append(opts, bbr.WithBucket(int(v)))
+	}
+	window := p.GetWindow()
+	if window == "" {
+		window = fallback.GetWindow()
+	}
+	if window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("overload: invalid window %q", window)
+		}
+		opts = append(opts, bbr.WithWindow(d))
+	}
+	return opts, nil
+}
+
+// serviceName 从 /package.Service/Method 形式的操作名中取出服务名，其他路由返回空字符串
+func serviceName(operation string) string {
+	op := strings.TrimPrefix(operation, "/")
+	i := strings.LastIndexByte(op, '/')
+	if i <= 0 || strings.Contains(op[:i], "/") {
+		return ""
+	}
+	return op[:i]
+}
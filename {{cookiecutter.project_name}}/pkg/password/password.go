@@ -0,0 +1,132 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+var (
+	// ErrUnknownAlgorithm 不支持的哈希算法
+	ErrUnknownAlgorithm = errors.New("password: unknown algorithm")
+	// ErrInvalidHash 哈希值格式错误
+	ErrInvalidHash = errors.New("password: invalid hash")
+)
+
+// Argon2Params argon2id 参数，默认值参考 RFC 9106 的第二推荐配置
+type Argon2Params struct {
+	Memory  uint32 // KiB
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// DefaultArgon2Params 默认 argon2id 参数
+var DefaultArgon2Params = Argon2Params{Memory: 64 * 1024, Time: 3, Threads: 2, SaltLen: 16, KeyLen: 32}
+
+// Hasher 按指定算法计算密码哈希，校验时根据哈希值自动识别算法
+type Hasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     Argon2Params
+}
+
+// New 创建 Hasher，algorithm 为空时使用 bcrypt
+func New(algorithm string) (*Hasher, error) {
+	switch algorithm {
+	case "":
+		algorithm = Bcrypt
+	case Bcrypt, Argon2id:
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownAlgorithm, algorithm)
+	}
+	return &Hasher{algorithm: algorithm, bcryptCost: bcrypt.DefaultCost, argon2: DefaultArgon2Params}, nil
+}
+
+// Hash 计算密码哈希，bcrypt 返回标准格式，argon2id 返回 PHC 格式
+// bcrypt 最多使用密码的前 72 字节，超出时返回错误
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == Argon2id {
+		p := h.argon2
+		salt := make([]byte, p.SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Time, p.Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	b, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Verify 校验密码是否与哈希值匹配
+func (h *Hasher) Verify(password, hash string) (bool, error) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		p, salt, key, err := decodeArgon2(hash)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+}
+
+// NeedsRehash 哈希值的算法或参数与当前配置不同时返回 true，可在登录成功后重新计算
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if h.algorithm == Argon2id {
+		p, _, _, err := decodeArgon2(hash)
+		return err != nil || p != h.argon2
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.bcryptCost
+}
+
+func decodeArgon2(hash string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != Argon2id {
+		return p, nil, nil, ErrInvalidHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	p.SaltLen, p.KeyLen = uint32(len(salt)), uint32(len(key))
+	return p, salt, key, nil
+}
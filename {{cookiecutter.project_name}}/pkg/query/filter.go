@@ -0,0 +1,434 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr 过滤表达式，可渲染为 SQL 或在内存中求值
+type Expr interface {
+	sql(fields Fields, b *strings.Builder, args *[]any)
+	match(get func(field string) any) bool
+}
+
+// logical AND / OR
+type logical struct {
+	op          string
+	left, right Expr
+}
+
+func (e *logical) sql(fields Fields, b *strings.Builder, args *[]any) {
+	b.WriteByte('(')
+	e.left.sql(fields, b, args)
+	b.WriteString(" " + e.op + " ")
+	e.right.sql(fields, b, args)
+	b.WriteByte(')')
+}
+
+func (e *logical) match(get func(string) any) bool {
+	if e.op == "AND" {
+		return e.left.match(get) && e.right.match(get)
+	}
+	return e.left.match(get) || e.right.match(get)
+}
+
+type not struct {
+	expr Expr
+}
+
+func (e *not) sql(fields Fields, b *strings.Builder, args *[]any) {
+	b.WriteString("NOT ")
+	e.expr.sql(fields, b, args)
+}
+
+func (e *not) match(get func(string) any) bool {
+	return !e.expr.match(get)
+}
+
+// comparison field op value，value 为 nil 表示 null
+type comparison struct {
+	field string
+	op    string
+	value any
+}
+
+func (e *comparison) sql(fields Fields, b *strings.Builder, args *[]any) {
+	column := fields[e.field]
+	switch {
+	case e.value == nil && e.op == "=":
+		b.WriteString(column + " IS NULL")
+	case e.value == nil:
+		b.WriteString(column + " IS NOT NULL")
+	case e.op == ":":
+		// "!" 作为转义符，避免不同数据库对反斜杠的处理差异
+		r := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+		b.WriteString(column + " LIKE ? ESCAPE '!'")
+		*args = append(*args, "%"+r.Replace(fmt.Sprint(e.value))+"%")
+	default:
+		b.WriteString(column + " " + e.op + " ?")
+		*args = append(*args, e.value)
+	}
+}
+
+func (e *comparison) match(get func(string) any) bool {
+	v := deref(get(e.field))
+	if e.value == nil {
+		return (v == nil) == (e.op == "=")
+	}
+	if v == nil {
+		return false
+	}
+	if e.op == ":" {
+		return contains(v, fmt.Sprint(e.value))
+	}
+	c, ok := compare(v, e.value)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// parseFilter 解析过滤表达式
+//
+//	expr       = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" expr ")" | field op value
+//	op         = "=" | "!=" | ">" | ">=" | "<" | "<=" | ":"
+//	value      = string | number | true | false | null
+func parseFilter(filter string, fields Fields) (Expr, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, invalidf("unexpected %q in filter", t.text)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	fields Fields
+}
+
+const maxFilterDepth = 32
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokenIdent && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	for err == nil && p.keyword("OR") {
+		var right Expr
+		if right, err = p.and(); err == nil {
+			left = &logical{op: "OR", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	for err == nil && p.keyword("AND") {
+		var right Expr
+		if right, err = p.unary(); err == nil {
+			left = &logical{op: "AND", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.depth++; p.depth > maxFilterDepth {
+		return nil, invalidf("filter is nested too deeply")
+	}
+	defer func() { p.depth-- }()
+
+	if p.keyword("NOT") {
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &not{expr: expr}, nil
+	}
+	if p.peek().kind == tokenLParen {
+		p.next()
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, invalidf("missing ')' in filter")
+		}
+		return expr, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (Expr, error) {
+	field := p.next()
+	if field.kind != tokenIdent {
+		return nil, invalidf("expected field name in filter, got %q", field.text)
+	}
+	if _, ok := p.fields[field.text]; !ok {
+		return nil, invalidf("field %q is not filterable", field.text)
+	}
+	op := p.next()
+	if op.kind != tokenOp {
+		return nil, invalidf("expected operator after %q in filter", field.text)
+	}
+	v := p.next()
+	e := &comparison{field: field.text, op: op.text}
+	switch v.kind {
+	case tokenString:
+		e.value = v.text
+	case tokenNumber:
+		if n, err := strconv.ParseInt(v.text, 10, 64); err == nil {
+			e.value = n
+		} else if f, err := strconv.ParseFloat(v.text, 64); err == nil {
+			e.value = f
+		} else {
+			return nil, invalidf("malformed number %q in filter", v.text)
+		}
+	case tokenIdent:
+		switch strings.ToLower(v.text) {
+		case "true":
+			e.value = true
+		case "false":
+			e.value = false
+		case "null":
+			if e.op != "=" && e.op != "!=" {
+				return nil, invalidf("null only supports = and !=")
+			}
+		default:
+			return nil, invalidf("unquoted value %q in filter", v.text)
+		}
+	default:
+		return nil, invalidf("expected value after %s %s in filter", field.text, op.text)
+	}
+	return e, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")"})
+			i++
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, invalidf("unterminated string in filter")
+			}
+			tokens = append(tokens, token{tokenString, b.String()})
+			i = j + 1
+		case strings.IndexByte("=!<>:", c) >= 0:
+			op := string(c)
+			if i+1 < len(s) && s[i+1] == '=' && c != '=' && c != ':' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, invalidf("unexpected '!' in filter")
+			}
+			tokens = append(tokens, token{tokenOp, op})
+			i += len(op)
+		case c == '-' || c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokenNumber, s[i:j]})
+			i = j
+		case isLetter(c):
+			j := i + 1
+			for j < len(s) && (isLetter(s[j]) || s[j] == '.' || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, token{tokenIdent, s[i:j]})
+			i = j
+		default:
+			return nil, invalidf("unexpected %q in filter", c)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// deref 解引用指针，nil 指针返回 nil
+func deref(v any) any {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+// compare 比较内存中的字段值与过滤值，类型不兼容时返回 false
+func compare(a, b any) (int, bool) {
+	a, b = deref(a), deref(b)
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		return cmp3(x, y), true
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	case bool:
+		y, ok := b.(bool)
+		if !ok || x == y {
+			return 0, ok
+		}
+		if !x {
+			return -1, true
+		}
+		return 1, true
+	case time.Time:
+		var y time.Time
+		switch v := b.(type) {
+		case time.Time:
+			y = v
+		case string:
+			var err error
+			if y, err = parseTime(v); err != nil {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+		return x.Compare(y), true
+	}
+	return 0, false
+}
+
+func contains(v any, sub string) bool {
+	switch x := v.(type) {
+	case string:
+		return strings.Contains(x, sub)
+	case []string:
+		for _, s := range x {
+			if strings.Contains(s, sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func cmp3(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("malformed time %q", s)
+}
@@ -0,0 +1,270 @@
+package query
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	maxFilterLength = 1024
+)
+
+// ErrInvalid 分页、过滤或排序参数不合法
+var ErrInvalid = errors.New("invalid query")
+
+// PageRequest 列表请求参数，由 api/v1/common.PageRequest 实现
+type PageRequest interface {
+	GetPageSize() int32
+	GetPageToken() string
+	GetOrderBy() string
+	GetFilter() string
+}
+
+// Fields 允许过滤与排序的字段，key 为 API 字段名，value 为 SQL 列名
+// 未在其中声明的字段一律拒绝，避免拼接任意列名
+type Fields map[string]string
+
+// Order 排序项
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Page 分页结果
+type Page[T any] struct {
+	Items         []T
+	NextPageToken string
+	TotalSize     int64
+}
+
+// Option 解析选项
+type Option func(*options)
+
+type options struct {
+	defaultOrder    string
+	tiebreak        string
+	defaultPageSize int
+	maxPageSize     int
+}
+
+// WithDefaultOrder 设置未指定 order_by 时的排序，格式同 order_by
+func WithDefaultOrder(orderBy string) Option {
+	return func(o *options) {
+		o.defaultOrder = orderBy
+	}
+}
+
+// WithTiebreak 设置排序的最后一个字段，通常为主键，保证分页结果稳定
+func WithTiebreak(field string) Option {
+	return func(o *options) {
+		o.tiebreak = field
+	}
+}
+
+// WithPageSize 设置默认与最大的每页数量
+func WithPageSize(def, max int) Option {
+	return func(o *options) {
+		o.defaultPageSize, o.maxPageSize = def, max
+	}
+}
+
+// Query 解析并校验后的列表查询
+type Query struct {
+	Filter Expr // 为 nil 表示不过滤
+	Orders []Order
+	Limit  int
+	Offset int
+
+	fields   Fields
+	checksum uint32
+}
+
+// Parse 解析 PageRequest，字段均按 fields 白名单校验，错误均包装 ErrInvalid
+func Parse(req PageRequest, fields Fields, opts ...Option) (*Query, error) {
+	o := options{defaultPageSize: defaultPageSize, maxPageSize: maxPageSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	q := &Query{fields: fields}
+	switch size := int(req.GetPageSize()); {
+	case size < 0:
+		return nil, invalidf("page_size must not be negative")
+	case size == 0:
+		q.Limit = o.defaultPageSize
+	case size > o.maxPageSize:
+		q.Limit = o.maxPageSize
+	default:
+		q.Limit = size
+	}
+
+	filter := strings.TrimSpace(req.GetFilter())
+	if len(filter) > maxFilterLength {
+		return nil, invalidf("filter exceeds %d characters", maxFilterLength)
+	}
+	if filter != "" {
+		expr, err := parseFilter(filter, fields)
+		if err != nil {
+			return nil, err
+		}
+		q.Filter = expr
+	}
+
+	orderBy := strings.TrimSpace(req.GetOrderBy())
+	if orderBy == "" {
+		orderBy = o.defaultOrder
+	}
+	orders, err := parseOrderBy(orderBy, fields)
+	if err != nil {
+		return nil, err
+	}
+	q.Orders = orders
+	if o.tiebreak != "" && !slices.ContainsFunc(orders, func(order Order) bool { return order.Field == o.tiebreak }) {
+		q.Orders = append(q.Orders, Order{Field: o.tiebreak})
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(filter + "\x00" + orderBy))
+	q.checksum = h.Sum32()
+	if token := req.GetPageToken(); token != "" {
+		if q.Offset, err = q.decodeToken(token); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// Where 返回 " WHERE ..." 子句及参数，无过滤条件时返回空串
+func (q *Query) Where() (string, []any) {
+	if q.Filter == nil {
+		return "", nil
+	}
+	var (
+		b    strings.Builder
+		args []any
+	)
+	b.WriteString(" WHERE ")
+	q.Filter.sql(q.fields, &b, &args)
+	return b.String(), args
+}
+
+// OrderBy 返回 " ORDER BY ..." 子句，无排序时返回空串
+func (q *Query) OrderBy() string {
+	if len(q.Orders) == 0 {
+		return ""
+	}
+	parts := make([]string, len(q.Orders))
+	for i, o := range q.Orders {
+		parts[i] = q.fields[o.Field]
+		if o.Desc {
+			parts[i] += " DESC"
+		}
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// NextPageToken 根据总数返回下一页的 page_token，没有更多数据时返回空串
+func (q *Query) NextPageToken(total int64) string {
+	next := q.Offset + q.Limit
+	if int64(next) >= total {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%x", next, q.checksum)))
+}
+
+// decodeToken 解析 page_token，filter 或 order_by 变化后旧的 token 失效
+func (q *Query) decodeToken(token string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, invalidf("malformed page_token")
+	}
+	offset, sum, ok := strings.Cut(string(raw), ":")
+	n, err := strconv.Atoi(offset)
+	if !ok || err != nil || n < 0 {
+		return 0, invalidf("malformed page_token")
+	}
+	if sum != strconv.FormatUint(uint64(q.checksum), 16) {
+		return 0, invalidf("page_token does not match filter or order_by")
+	}
+	return n, nil
+}
+
+// Apply 在内存中执行查询，get 返回元素指定 API 字段的值
+func Apply[T any](q *Query, items []T, get func(item T, field string) any) *Page[T] {
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		if q.Filter == nil || q.Filter.match(func(field string) any { return get(item, field) }) {
+			matched = append(matched, item)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, o := range q.Orders {
+			c, ok := compare(get(matched[i], o.Field), get(matched[j], o.Field))
+			if !ok || c == 0 {
+				continue
+			}
+			return (c < 0) != o.Desc
+		}
+		return false
+	})
+
+	page := &Page[T]{TotalSize: int64(len(matched)), NextPageToken: q.NextPageToken(int64(len(matched)))}
+	if q.Offset < len(matched) {
+		page.Items = matched[q.Offset:min(q.Offset+q.Limit, len(matched))]
+	}
+	return page
+}
+
+func parseOrderBy(orderBy string, fields Fields) ([]Order, error) {
+	if orderBy == "" {
+		return nil, nil
+	}
+	var orders []Order
+	for _, part := range strings.Split(orderBy, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 || len(words) > 2 {
+			return nil, invalidf("malformed order_by %q", part)
+		}
+		o := Order{Field: words[0]}
+		if _, ok := fields[o.Field]; !ok {
+			return nil, invalidf("field %q is not sortable", o.Field)
+		}
+		if len(words) == 2 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				o.Desc = true
+			default:
+				return nil, invalidf("malformed order_by %q", part)
+			}
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// Quote 将字符串转换为过滤表达式中的字符串字面量
+func Quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// invalidError 参数错误，errors.Is(err, ErrInvalid) 为 true
+type invalidError struct {
+	msg string
+}
+
+func (e *invalidError) Error() string { return e.msg }
+
+func (e *invalidError) Is(target error) bool { return target == ErrInvalid }
+
+func invalidf(format string, args ...any) error {
+	return &invalidError{msg: fmt.Sprintf(format, args...)}
+}
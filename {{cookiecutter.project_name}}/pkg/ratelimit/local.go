@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// LocalLimiter 进程内令牌桶，每个 key 一个桶，按 Limit/Window 的速率补充令牌，容量为 Burst
+// 多实例部署时每个实例各自计数
+type LocalLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full 令牌补满的时间，之后可以回收该桶
+	full time.Time
+}
+
+var _ Limiter = (*LocalLimiter)(nil)
+
+// NewLocalLimiter 创建进程内限流存储
+func NewLocalLimiter() *LocalLimiter {
+	return &LocalLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Allow 实现 Limiter
+func (l *LocalLimiter) Allow(_ context.Context, key string, rule *Rule) (Result, error) {
+	now := l.now()
+	rate := float64(rule.Limit) / rule.Window.Seconds()
+	capacity := float64(rule.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := Result{Limit: rule.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int64(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+// sweep 定期回收已补满的桶，避免 key 数量无限增长
+func (l *LocalLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for k, b := range l.buckets {
+		if !now.Before(b.full) {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
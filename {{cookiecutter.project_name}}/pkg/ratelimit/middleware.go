@@ -0,0 +1,202 @@
+package ratelimit
+
+import (
+	"context"
+	"{{cookiecutter.project_name}}/pkg/auth"
+	"{{cookiecutter.project_name}}/pkg/http/response"
+	"{{cookiecutter.project_name}}/pkg/metric"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/peer"
+)
+
+// 限流失败的错误原因
+const (
+	// ReasonRateLimited 超出限制，响应码为 response.RateLimitAllowFailed
+	ReasonRateLimited = "RATE_LIMITED"
+	// ReasonRateLimitError 限流存储出错，响应码为 response.RateLimitAllowErrFailed
+	ReasonRateLimitError = "RATE_LIMIT_ERROR"
+)
+
+// 响应头，见 draft-ietf-httpapi-ratelimit-headers
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+var (
+	ErrRateLimited    = errors.New(429, ReasonRateLimited, "rate limit exceeded")
+	ErrRateLimitError = errors.ServiceUnavailable(ReasonRateLimitError, "rate limiter error")
+)
+
+func init() {
+	response.RegisterReason(ReasonRateLimited, response.RateLimitAllowFailed)
+	response.RegisterReason(ReasonRateLimitError, response.RateLimitAllowErrFailed)
+}
+
+// Server 限流中间件，需放在认证中间件之后才能按 user 限流
+// 请求依次检查全部匹配的规则，响应头返回剩余额度最少的规则
+func Server(rl *RateLimiter, logger log.Logger) middleware.Middleware {
+	helper := log.NewHelper(logger)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			operation := tr.Operation()
+			path := ""
+			if r, ok := http.RequestFromServerContext(ctx); ok {
+				path = r.URL.Path
+			}
+
+			var tightest *Result
+			for _, rule := range rl.rules {
+				if !rule.match.match(operation, path) {
+					continue
+				}
+				res, err := rl.limiter.Allow(ctx, rl.key(ctx, tr, rule), rule)
+				if err != nil {
+					helper.WithContext(ctx).Errorf("ratelimit: rule %s: %v", rule.Name, err)
+					if rl.failOpen {
+						continue
+					}
+					return nil, ErrRateLimitError.WithCause(err)
+				}
+				if !res.Allowed {
+					metric.RateLimitRejectedCount.With(rule.Name, operation).Inc()
+					setHeaders(tr, &res)
+					return nil, ErrRateLimited
+				}
+				if tightest == nil || res.Remaining < tightest.Remaining {
+					tightest = &res
+				}
+			}
+			if tightest != nil {
+				setHeaders(tr, tightest)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// key 拼接规则名称与各维度的值
+func (rl *RateLimiter) key(ctx context.Context, tr transport.Transporter, rule *Rule) string {
+	var b strings.Builder
+	b.WriteString(rule.Name)
+	for _, k := range rule.Keys {
+		b.WriteByte('|')
+		switch {
+		case k == KeyRoute:
+			b.WriteString(tr.Operation())
+		case k == KeyIP:
+			b.WriteString(rl.clientIP(ctx, tr))
+		case k == KeyUser:
+			if sub := auth.Subject(ctx); sub != "" {
+				b.WriteString("u:" + sub)
+			} else {
+				b.WriteString("ip:" + rl.clientIP(ctx, tr))
+			}
+		default:
+			b.WriteString(tr.RequestHeader().Get(strings.TrimPrefix(k, keyHeaderPrefix)))
+		}
+	}
+	return b.String()
+}
+
+// clientIP 返回客户端 IP，trustProxy 时优先使用可信代理转发的地址
+// X-Forwarded-For 左侧的地址可由客户端任意伪造，只采用从右数第 trustedHops 个地址
+func (rl *RateLimiter) clientIP(ctx context.Context, tr transport.Transporter) string {
+	if rl.trustProxy {
+		if xff := headerList(tr.RequestHeader().Values("X-Forwarded-For")); len(xff) > 0 {
+			if len(xff) >= rl.trustedHops {
+				return xff[len(xff)-rl.trustedHops]
+			}
+		} else if ip := headerList(tr.RequestHeader().Values("X-Real-IP")); len(ip) > 0 {
+			return ip[len(ip)-1]
+		}
+	}
+	addr := ""
+	if r, ok := http.RequestFromServerContext(ctx); ok {
+		addr = r.RemoteAddr
+	} else if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func setHeaders(tr transport.Transporter, res *Result) {
+	h := tr.ReplyHeader()
+	h.Set(HeaderLimit, strconv.FormatInt(res.Limit, 10))
+	h.Set(HeaderRemaining, strconv.FormatInt(res.Remaining, 10))
+	h.Set(HeaderReset, ceilSeconds(res.Reset))
+	if !res.Allowed {
+		h.Set(HeaderRetryAfter, ceilSeconds(res.RetryAfter))
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// matcher 规则生效的操作与路径，以 * 结尾表示前缀匹配
+type matcher struct {
+	all      bool
+	exact    map[string]struct{}
+	prefixes []string
+}
+
+func newMatcher(patterns []string) *matcher {
+	m := &matcher{all: len(patterns) == 0, exact: make(map[string]struct{})}
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			m.prefixes = append(m.prefixes, prefix)
+		} else if p != "" {
+			m.exact[p] = struct{}{}
+		}
+	}
+	return m
+}
+
+func (m *matcher) match(operation, path string) bool {
+	return m.all || m.contains(operation) || path != "" && m.contains(path)
+}
+
+func (m *matcher) contains(s string) bool {
+	if _, ok := m.exact[s]; ok {
+		return true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerList 将多行、逗号分隔的请求头拆分为地址列表，顺序与代理追加的顺序一致
+func headerList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"{{cookiecutter.project_name}}/configs/conf"
+	"strings"
+	"time"
+)
+
+const (
+	ModeLocal = "local" // 进程内令牌桶
+	ModeRedis = "redis" // Redis 滑动窗口
+
+	KeyRoute        = "route"
+	KeyIP           = "ip"
+	KeyUser         = "user"
+	keyHeaderPrefix = "header:"
+)
+
+// Rule 限流规则
+type Rule struct {
+	Name   string
+	Match  []string
+	Keys   []string
+	Limit  int64
+	Window time.Duration
+	// Burst 令牌桶容量，仅 local 模式使用
+	Burst int64
+
+	match *matcher
+}
+
+// Result 一次限流检查的结果
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// Reset 额度完全恢复所需的时间
+	Reset time.Duration
+	// RetryAfter 被拒绝时距下次可请求的时间
+	RetryAfter time.Duration
+}
+
+// Limiter 限流存储，key 已包含规则名称
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule *Rule) (Result, error)
+}
+
+// RateLimiter 按规则限流
+type RateLimiter struct {
+	limiter    Limiter
+	rules      []*Rule
+	trustProxy bool
+	// trustedHops 追加 X-Forwarded-For 的可信代理层数
+	trustedHops int
+	failOpen    bool
+}
+
+// New 根据配置创建限流器，limiter 为 local 或 redis 模式对应的存储
+func New(c *conf.Server_RateLimit, limiter Limiter) (*RateLimiter, error) {
+	rl := &RateLimiter{limiter: limiter, trustProxy: c.GetTrustProxy(), trustedHops: int(c.GetTrustedHops()), failOpen: c.GetFailOpen()}
+	if rl.trustedHops == 0 {
+		rl.trustedHops = 1
+	}
+	for i, rc := range c.GetRules() {
+		r := &Rule{
+			Name:  rc.GetName(),
+			Match: rc.GetMatch(),
+			Keys:  rc.GetKey(),
+			Limit: rc.GetLimit(),
+			Burst: rc.GetBurst(),
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule%d", i)
+		}
+		if len(r.Keys) == 0 {
+			r.Keys = []string{KeyRoute}
+		}
+		for _, k := range r.Keys {
+			if k != KeyRoute && k != KeyIP && k != KeyUser && !(strings.HasPrefix(k, keyHeaderPrefix) && len(k) > len(keyHeaderPrefix)) {
+				return nil, fmt.Errorf("ratelimit: rule %s has unknown key %q", r.Name, k)
+			}
+		}
+		if r.Limit <= 0 {
+			return nil, fmt.Errorf("ratelimit: rule %s requires a positive limit", r.Name)
+		}
+		window, err := time.ParseDuration(rc.GetWindow())
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("ratelimit: rule %s has invalid window %q", r.Name, rc.GetWindow())
+		}
+		r.Window = window
+		if r.Burst <= 0 {
+			r.Burst = r.Limit
+		}
+		r.match = newMatcher(r.Match)
+		rl.rules = append(rl.rules, r)
+	}
+	return rl, nil
+}
+
+// Rules 返回解析后的规则
+func (rl *RateLimiter) Rules() []*Rule {
+	return rl.rules
+}
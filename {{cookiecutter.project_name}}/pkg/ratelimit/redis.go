@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindow 使用有序集合记录窗口内每个请求的时间，时间取 Redis 服务器时间，避免实例间时钟偏差
+// KEYS[1] 计数 key；ARGV[1] 窗口毫秒数；ARGV[2] 限制数；ARGV[3] 请求唯一标识
+// 返回 {是否放行, 窗口内请求数, 最早请求过期的毫秒数}
+var slidingWindow = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now, ARGV[3])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// RedisLimiter 基于 Redis 的分布式滑动窗口，所有实例共享计数
+type RedisLimiter struct {
+	rdb      redis.UniversalClient
+	prefix   string
+	instance string
+	seq      atomic.Uint64
+}
+
+var _ Limiter = (*RedisLimiter)(nil)
+
+// NewRedisLimiter 创建 Redis 限流存储，key 为 prefix + 规则名称 + 限流维度
+func NewRedisLimiter(rdb redis.UniversalClient, prefix string) *RedisLimiter {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return &RedisLimiter{rdb: rdb, prefix: prefix, instance: hex.EncodeToString(b[:])}
+}
+
+// Allow 实现 Limiter
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rule *Rule) (Result, error) {
+	member := l.instance + ":" + strconv.FormatUint(l.seq.Add(1), 36)
+	vals, err := slidingWindow.Run(ctx, l.rdb, []string{l.prefix + key},
+		rule.Window.Milliseconds(), rule.Limit, member).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{
+		Allowed:   vals[0] == 1,
+		Limit:     rule.Limit,
+		Remaining: max(rule.Limit-vals[1], 0),
+		Reset:     time.Duration(vals[2]) * time.Millisecond,
+	}
+	if !res.Allowed {
+		res.RetryAfter = res.Reset
+	}
+	return res, nil
+}
@@ -0,0 +1,513 @@
+package sheet
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"{{cookiecutter.project_name}}/pkg/middleware"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/xuri/excelize/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	// ErrNoHeader 表头中没有任何列与字段匹配
+	ErrNoHeader = errors.New("sheet: no column of the header matches a field")
+	// ErrTooManyRows 数据行数超过 WithMaxRows
+	ErrTooManyRows = errors.New("sheet: too many rows")
+)
+
+const timestampName = "google.protobuf.Timestamp"
+
+// Column 表格的一列，Field 为 proto 字段的 json 名称
+type Column struct {
+	Header string
+	Field  string
+}
+
+// Columns 返回消息 m 中 fields 对应的列，表头为字段的 json 名称，fields 为空时返回全部支持的字段
+// 支持标量、枚举、google.protobuf.Timestamp 及它们的 repeated 字段，不支持 map 与其他消息字段
+func Columns(m proto.Message, fields ...string) ([]Column, error) {
+	md := m.ProtoReflect().Descriptor()
+	if len(fields) == 0 {
+		cols := make([]Column, 0, md.Fields().Len())
+		for i := 0; i < md.Fields().Len(); i++ {
+			if fd := md.Fields().Get(i); supported(fd) {
+				cols = append(cols, Column{Header: fd.JSONName(), Field: fd.JSONName()})
+			}
+		}
+		return cols, nil
+	}
+	cols := make([]Column, 0, len(fields))
+	for _, name := range fields {
+		fd, err := lookup(md, name)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, Column{Header: fd.JSONName(), Field: fd.JSONName()})
+	}
+	return cols, nil
+}
+
+func lookup(md protoreflect.MessageDescriptor, name string) (protoreflect.FieldDescriptor, error) {
+	fd := md.Fields().ByJSONName(name)
+	if fd == nil {
+		fd = md.Fields().ByName(protoreflect.Name(name))
+	}
+	if fd == nil {
+		return nil, fmt.Errorf("sheet: %s has no field %q", md.FullName(), name)
+	}
+	if !supported(fd) {
+		return nil, fmt.Errorf("sheet: field %q of type %s is not supported", name, fd.Kind())
+	}
+	return fd, nil
+}
+
+func supported(fd protoreflect.FieldDescriptor) bool {
+	switch {
+	case fd.IsMap(), fd.Kind() == protoreflect.GroupKind:
+		return false
+	case fd.Kind() == protoreflect.MessageKind:
+		return fd.Message().FullName() == timestampName
+	}
+	return true
+}
+
+// resolve 返回各列在 md 中的字段
+func resolve(md protoreflect.MessageDescriptor, cols []Column) ([]protoreflect.FieldDescriptor, error) {
+	fds := make([]protoreflect.FieldDescriptor, len(cols))
+	for i, c := range cols {
+		fd, err := lookup(md, c.Field)
+		if err != nil {
+			return nil, err
+		}
+		fds[i] = fd
+	}
+	return fds, nil
+}
+
+// RowError 导入时一行的错误，Field 为空表示整行的错误
+type RowError struct {
+	// Row 行号，表头所在行为第 1 行
+	Row     int
+	Field   string
+	Message string
+}
+
+// RowErrors 一行中全部单元格的转换与校验错误
+type RowErrors []RowError
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, re := range e {
+		msgs[i] = fmt.Sprintf("row %d %s: %s", re.Row, re.Field, re.Message)
+	}
+	return "sheet: " + strings.Join(msgs, "; ")
+}
+
+// Decoder 将表格的数据行解析为 proto 消息，第一个非空行为表头
+type Decoder struct {
+	r    Reader
+	cols []Column
+	// index 表头第 i 个单元格对应的列，-1 表示忽略该单元格
+	index []int
+	fds   []protoreflect.FieldDescriptor
+	md    protoreflect.MessageDescriptor
+	row   int
+	count int
+	opts  options
+}
+
+// NewDecoder 读取表头并创建解析器，表头按 Column.Header、字段的 json 名称或 proto 名称匹配，忽略大小写
+// 表头中不存在的列保持字段的零值，无法匹配的表头单元格被忽略
+func NewDecoder(r Reader, cols []Column, opts ...Option) (*Decoder, error) {
+	d := &Decoder{r: r, cols: cols, opts: newOptions(opts)}
+	var header []string
+	for {
+		cells, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoHeader
+		}
+		if err != nil {
+			return nil, err
+		}
+		d.row++
+		if !blank(cells) {
+			header = cells
+			break
+		}
+	}
+
+	names := make(map[string]int, len(cols)*2)
+	for i, c := range cols {
+		names[strings.ToLower(c.Header)] = i
+		names[strings.ToLower(c.Field)] = i
+	}
+	d.index = make([]int, len(header))
+	seen := make(map[int]bool, len(cols))
+	for i, h := range header {
+		col, ok := names[strings.ToLower(strings.TrimSpace(h))]
+		if !ok {
+			d.index[i] = -1
+			continue
+		}
+		if seen[col] {
+			return nil, fmt.Errorf("sheet: duplicate column %q", h)
+		}
+		seen[col] = true
+		d.index[i] = col
+	}
+	if len(seen) == 0 {
+		return nil, ErrNoHeader
+	}
+	return d, nil
+}
+
+// Row 返回最后读取的行号
+func (d *Decoder) Row() int {
+	return d.row
+}
+
+// Decode 读取下一个非空数据行到 m，m 会先被重置，读完时返回 io.EOF
+// 单元格无法转换或 m 未通过 protoc-gen-validate 生成的校验时返回 RowErrors
+func (d *Decoder) Decode(m proto.Message) error {
+	if err := d.bind(m); err != nil {
+		return err
+	}
+	var cells []string
+	for {
+		var err error
+		if cells, err = d.r.Read(); errors.Is(err, io.EOF) {
+			return err
+		}
+		d.row++
+		if err != nil {
+			return err
+		}
+		if !blank(cells) {
+			break
+		}
+	}
+	if d.opts.maxRows > 0 && d.count >= d.opts.maxRows {
+		return fmt.Errorf("%w: more than %d", ErrTooManyRows, d.opts.maxRows)
+	}
+	d.count++
+
+	proto.Reset(m)
+	rm := m.ProtoReflect()
+	var errs RowErrors
+	for i, cell := range cells {
+		if i >= len(d.index) || d.index[i] < 0 {
+			continue
+		}
+		v := strings.TrimSpace(cell)
+		if v == "" {
+			continue
+		}
+		col := d.index[i]
+		if err := d.set(rm, d.fds[col], v); err != nil {
+			errs = append(errs, RowError{Row: d.row, Field: d.cols[col].Field, Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if v, ok := m.(interface{ ValidateAll() error }); ok {
+		if err := v.ValidateAll(); err != nil {
+			violations := middleware.Violations(err)
+			fields := make([]string, 0, len(violations))
+			for f := range violations {
+				fields = append(fields, f)
+			}
+			slices.Sort(fields)
+			for _, f := range fields {
+				errs = append(errs, RowError{Row: d.row, Field: f, Message: violations[f]})
+			}
+			return errs
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) bind(m proto.Message) error {
+	md := m.ProtoReflect().Descriptor()
+	if d.md == md {
+		return nil
+	}
+	fds, err := resolve(md, d.cols)
+	if err != nil {
+		return err
+	}
+	d.md, d.fds = md, fds
+	return nil
+}
+
+func (d *Decoder) set(m protoreflect.Message, fd protoreflect.FieldDescriptor, s string) error {
+	if !fd.IsList() {
+		v, err := d.parse(fd, s)
+		if err != nil {
+			return err
+		}
+		m.Set(fd, v)
+		return nil
+	}
+	list := m.Mutable(fd).List()
+	for _, item := range strings.Split(s, Separator) {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		v, err := d.parse(fd, item)
+		if err != nil {
+			return err
+		}
+		list.Append(v)
+	}
+	return nil
+}
+
+func (d *Decoder) parse(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	invalid := func() (protoreflect.Value, error) {
+		return protoreflect.Value{}, fmt.Errorf("invalid %s value %q", fd.Kind(), s)
+	}
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfBool(b), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfInt32(int32(n)), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfInt64(n), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfUint32(uint32(n)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfUint64(n), nil
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfFloat32(float32(f)), nil
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfFloat64(f), nil
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfBytes(b), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || fd.Enum().Values().ByNumber(protoreflect.EnumNumber(n)) == nil {
+			return invalid()
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	case protoreflect.MessageKind:
+		t, ok := d.parseTime(s)
+		if !ok {
+			return invalid()
+		}
+		return protoreflect.ValueOfMessage(timestamppb.New(t).ProtoReflect()), nil
+	}
+	return invalid()
+}
+
+// parseTime 依次尝试 WithTimeLayout、RFC3339、日期与 Excel 日期序列号
+func (d *Decoder) parseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{d.opts.timeLayout, time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, d.opts.location); err == nil {
+			return t, true
+		}
+	}
+	serial, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := excelize.ExcelDateToTime(serial, false)
+	if err != nil {
+		return time.Time{}, false
+	}
+	// 序列号为不带时区的本地时间
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), d.opts.location), true
+}
+
+// Report 导入结果
+type Report struct {
+	// Total 处理的数据行数
+	Total     int
+	Succeeded int
+	Failed    int
+	// Errors 失败行的错误，最多保留 WithMaxErrors 条，导入中止时最后一条为中止的原因
+	Errors []RowError
+}
+
+func (r *Report) fail(errs ...RowError) {
+	r.Failed++
+	for _, e := range errs {
+		if len(r.Errors) >= cap(r.Errors) {
+			return
+		}
+		r.Errors = append(r.Errors, e)
+	}
+}
+
+// Import 逐行解析 d 中的数据并调用 save 保存，解析失败或 save 返回 4xx 错误的行记入报告后继续导入
+// 读取失败或超过 WithMaxRows 时停止导入，错误记入报告，已保存的行保留
+// ctx 结束或 save 返回 5xx 错误时停止导入并返回错误
+func Import[T proto.Message](ctx context.Context, d *Decoder, save func(context.Context, T) error) (*Report, error) {
+	var zero T
+	mt := zero.ProtoReflect().Type()
+	report := &Report{Errors: make([]RowError, 0, d.opts.maxErrors)}
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		m := mt.New().Interface().(T)
+		err := d.Decode(m)
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		var rowErrs RowErrors
+		if errors.As(err, &rowErrs) {
+			report.Total++
+			report.fail(rowErrs...)
+			continue
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, RowError{Row: d.row, Message: err.Error() + ", the remaining rows are not imported"})
+			return report, nil
+		}
+		report.Total++
+		if err := save(ctx, m); err != nil {
+			se := kerrors.FromError(err)
+			if se.Code >= 500 {
+				return report, err
+			}
+			report.fail(RowError{Row: d.row, Message: se.Message})
+			continue
+		}
+		report.Succeeded++
+	}
+}
+
+// Encoder 将 proto 消息逐行写入表格
+type Encoder struct {
+	w    Writer
+	cols []Column
+	fds  []protoreflect.FieldDescriptor
+	md   protoreflect.MessageDescriptor
+	opts options
+}
+
+// NewEncoder 写入表头并创建编码器，写入完成后由调用方关闭 w
+func NewEncoder(w Writer, cols []Column, opts ...Option) (*Encoder, error) {
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.Header
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Encoder{w: w, cols: cols, opts: newOptions(opts)}, nil
+}
+
+// Encode 写入一行，未设置的字段为空单元格，repeated 字段以 Separator 连接
+func (e *Encoder) Encode(m proto.Message) error {
+	rm := m.ProtoReflect()
+	if md := rm.Descriptor(); e.md != md {
+		fds, err := resolve(md, e.cols)
+		if err != nil {
+			return err
+		}
+		e.md, e.fds = md, fds
+	}
+	row := make([]string, len(e.fds))
+	for i, fd := range e.fds {
+		if fd.IsList() {
+			list := rm.Get(fd).List()
+			items := make([]string, list.Len())
+			for j := range items {
+				items[j] = e.format(fd, list.Get(j))
+			}
+			row[i] = strings.Join(items, Separator)
+		} else if rm.Has(fd) {
+			row[i] = e.format(fd, rm.Get(fd))
+		}
+	}
+	return e.w.Write(row)
+}
+
+func (e *Encoder) format(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(v.Bool())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10)
+	case protoreflect.FloatKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case protoreflect.DoubleKind:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.MessageKind:
+		if ts, ok := v.Message().Interface().(*timestamppb.Timestamp); ok {
+			return ts.AsTime().In(e.opts.location).Format(e.opts.timeLayout)
+		}
+		return ""
+	}
+	return v.String()
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
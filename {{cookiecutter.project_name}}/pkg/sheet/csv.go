@@ -0,0 +1,53 @@
+package sheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+)
+
+// utf8BOM Excel 打开不带 BOM 的 UTF-8 CSV 时中文会乱码
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type csvReader struct {
+	r *csv.Reader
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	br := bufio.NewReader(r)
+	if head, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(head, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	return &csvReader{r: cr}
+}
+
+func (r *csvReader) Read() ([]string, error) {
+	return r.r.Read()
+}
+
+func (r *csvReader) Close() error {
+	return nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (Writer, error) {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (w *csvWriter) Write(row []string) error {
+	return w.w.Write(row)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
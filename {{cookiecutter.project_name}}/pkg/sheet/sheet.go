@@ -0,0 +1,138 @@
+// Package sheet 将 CSV/Excel 表格的行映射为 proto 消息，用于批量导入与流式导出
+package sheet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Format 表格格式
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ErrUnknownFormat 不支持的表格格式
+var ErrUnknownFormat = errors.New("sheet: unknown format")
+
+// ParseFormat 解析格式名称，如 "csv"、"xlsx"，为空时返回 XLSX
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", XLSX:
+		return XLSX, nil
+	case CSV:
+		return CSV, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, s)
+}
+
+// FormatOf 根据文件扩展名返回格式
+func FormatOf(filename string) (Format, error) {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+	if ext == "" {
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, filename)
+	}
+	return ParseFormat(ext)
+}
+
+// ContentType 返回格式的 MIME 类型
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// Reader 逐行读取表格，读完时返回 io.EOF
+type Reader interface {
+	Read() ([]string, error)
+	Close() error
+}
+
+// Writer 逐行写入表格，Close 时写出剩余内容
+type Writer interface {
+	Write(row []string) error
+	Close() error
+}
+
+// NewReader 创建读取器，xlsx 读取第一个工作表
+func NewReader(r io.Reader, f Format) (Reader, error) {
+	switch f {
+	case CSV:
+		return newCSVReader(r), nil
+	case XLSX:
+		return newXLSXReader(r)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, f)
+}
+
+// NewWriter 创建写入器，xlsx 超过内存阈值的行写入临时文件，Close 时再写入 w
+func NewWriter(w io.Writer, f Format) (Writer, error) {
+	switch f {
+	case CSV:
+		return newCSVWriter(w)
+	case XLSX:
+		return newXLSXWriter(w)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, f)
+}
+
+// Separator 重复字段在单元格中的分隔符
+const Separator = ";"
+
+const (
+	defaultTimeLayout = time.DateTime
+	defaultMaxErrors  = 100
+)
+
+// Option 导入导出选项
+type Option func(*options)
+
+type options struct {
+	timeLayout string
+	location   *time.Location
+	maxRows    int
+	maxErrors  int
+}
+
+// WithTimeLayout 时间字段的格式，默认 "2006-01-02 15:04:05"，导入时也接受 RFC3339 与 "2006-01-02"
+func WithTimeLayout(layout string) Option {
+	return func(o *options) {
+		o.timeLayout = layout
+	}
+}
+
+// WithLocation 时间字段的时区，默认 time.Local
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// WithMaxRows 导入的最大数据行数，超过时返回 ErrTooManyRows，默认不限制
+func WithMaxRows(n int) Option {
+	return func(o *options) {
+		o.maxRows = n
+	}
+}
+
+// WithMaxErrors 导入报告中保留的最大错误数，默认 100
+func WithMaxErrors(n int) Option {
+	return func(o *options) {
+		o.maxErrors = n
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{timeLayout: defaultTimeLayout, location: time.Local, maxErrors: defaultMaxErrors}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
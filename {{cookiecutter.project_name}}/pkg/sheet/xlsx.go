@@ -0,0 +1,91 @@
+package sheet
+
+import (
+	"errors"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	xlsxSheet = "Sheet1"
+	// xlsxUnzipLimit 解压后的大小上限，防止压缩炸弹
+	xlsxUnzipLimit = 256 << 20
+)
+
+type xlsxReader struct {
+	f    *excelize.File
+	rows *excelize.Rows
+}
+
+// newXLSXReader 需读入整个文件，工作表的行按需解析，较大的工作表解压到临时文件
+func newXLSXReader(r io.Reader) (Reader, error) {
+	f, err := excelize.OpenReader(r, excelize.Options{UnzipSizeLimit: xlsxUnzipLimit})
+	if err != nil {
+		return nil, err
+	}
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		_ = f.Close()
+		return nil, errors.New("sheet: workbook has no sheet")
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &xlsxReader{f: f, rows: rows}, nil
+}
+
+// Read 返回单元格的原始值，数字不带格式，日期为 Excel 序列号
+func (r *xlsxReader) Read() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return r.rows.Columns(excelize.Options{RawCellValue: true})
+}
+
+func (r *xlsxReader) Close() error {
+	return errors.Join(r.rows.Close(), r.f.Close())
+}
+
+type xlsxWriter struct {
+	w   io.Writer
+	f   *excelize.File
+	sw  *excelize.StreamWriter
+	row int
+}
+
+func newXLSXWriter(w io.Writer) (Writer, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &xlsxWriter{w: w, f: f, sw: sw}, nil
+}
+
+func (w *xlsxWriter) Write(row []string) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(row))
+	for i, v := range row {
+		values[i] = v
+	}
+	return w.sw.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.f.Close()
+	if err := w.sw.Flush(); err != nil {
+		return err
+	}
+	return w.f.Write(w.w)
+}
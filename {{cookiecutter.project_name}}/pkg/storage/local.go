@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSignatureInvalid 下载地址的签名不正确
+	ErrSignatureInvalid = errors.New("storage: invalid signature")
+	// ErrURLExpired 下载地址已过期
+	ErrURLExpired = errors.New("storage: url has expired")
+)
+
+// Local 本地文件系统存储
+// 下载地址指向 baseURL，由应用调用 Verify 校验签名后通过 Open 返回文件
+type Local struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+var _ Storage = (*Local)(nil)
+
+// LocalOption 本地存储选项
+type LocalOption func(*Local)
+
+// WithBaseURL 下载地址前缀，如 "/api/v1/files/download" 或 "https://example.com/api/v1/files/download"
+func WithBaseURL(baseURL string) LocalOption {
+	return func(l *Local) {
+		l.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithSecret 下载地址的签名密钥，多实例部署时需使用相同的密钥，未设置时每次启动随机生成
+func WithSecret(secret []byte) LocalOption {
+	return func(l *Local) {
+		l.secret = secret
+	}
+}
+
+// NewLocal 创建本地存储，root 不存在时自动创建
+func NewLocal(root string, opts ...LocalOption) (*Local, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: create root: %w", err)
+	}
+	l := &Local{root: abs}
+	for _, o := range opts {
+		o(l)
+	}
+	if len(l.secret) == 0 {
+		l.secret = make([]byte, 32)
+		if _, err := rand.Read(l.secret); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put 实现 Storage，先写入同目录的临时文件，完成后再重命名
+func (l *Local) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) (err error) {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Open 实现 Storage，返回的 *os.File 支持 Seek
+func (l *Local) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotExist
+	}
+	return f, err
+}
+
+// Delete 实现 Storage
+func (l *Local) Delete(_ context.Context, key string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// PresignGet 实现 Storage，返回 baseURL/key?expires=&filename=&signature=
+func (l *Local) PresignGet(_ context.Context, key, filename string, expire time.Duration) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(time.Now().Add(expire).Unix(), 10)
+	q := url.Values{"expires": {expires}, "signature": {l.sign(key, expires, filename)}}
+	if filename != "" {
+		q.Set("filename", filename)
+	}
+	return l.baseURL + "/" + (&url.URL{Path: key}).EscapedPath() + "?" + q.Encode(), nil
+}
+
+// Verify 校验 PresignGet 返回的下载地址，q 为地址的查询参数
+func (l *Local) Verify(key string, q url.Values) error {
+	expires := q.Get("expires")
+	sig, err := base64.RawURLEncoding.DecodeString(q.Get("signature"))
+	if err != nil || !hmac.Equal(sig, l.mac(key, expires, q.Get("filename"))) {
+		return ErrSignatureInvalid
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	if time.Now().Unix() > unix {
+		return ErrURLExpired
+	}
+	return nil
+}
+
+func (l *Local) sign(key, expires, filename string) string {
+	return base64.RawURLEncoding.EncodeToString(l.mac(key, expires, filename))
+}
+
+func (l *Local) mac(key, expires, filename string) []byte {
+	h := hmac.New(sha256.New, l.secret)
+	h.Write([]byte(key + "\n" + expires + "\n" + filename))
+	return h.Sum(nil)
+}
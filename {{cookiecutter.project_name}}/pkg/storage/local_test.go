@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLocal(t *testing.T) *Local {
+	t.Helper()
+	l, err := NewLocal(t.TempDir(), WithBaseURL("/api/v1/files/download/"), WithSecret([]byte("secret")))
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	return l
+}
+
+func readAll(t *testing.T, s Storage, key string) string {
+	t.Helper()
+	rc, err := s.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", key, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read %q: %v", key, err)
+	}
+	return string(data)
+}
+
+func TestLocalPutOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	l := newLocal(t)
+
+	if err := l.Put(ctx, "2024/01/a.txt", strings.NewReader("hello"), -1, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := readAll(t, l, "2024/01/a.txt"); got != "hello" {
+		t.Fatalf("content = %q, want hello", got)
+	}
+	// 覆盖写入
+	if err := l.Put(ctx, "2024/01/a.txt", strings.NewReader("world"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if got := readAll(t, l, "2024/01/a.txt"); got != "world" {
+		t.Fatalf("content = %q, want world", got)
+	}
+
+	if err := l.Delete(ctx, "2024/01/a.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := l.Open(ctx, "2024/01/a.txt"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("Open after Delete = %v, want ErrNotExist", err)
+	}
+	if err := l.Delete(ctx, "2024/01/a.txt"); err != nil {
+		t.Fatalf("Delete of a missing object = %v, want nil", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestLocalPutFailure(t *testing.T) {
+	l := newLocal(t)
+	r := io.MultiReader(strings.NewReader("partial"), failingReader{})
+	if err := l.Put(context.Background(), "a/b.txt", r, -1, ""); err == nil {
+		t.Fatal("Put should fail when the reader fails")
+	}
+	entries, err := os.ReadDir(filepath.Join(l.root, "a"))
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("failed Put left %d files", len(entries))
+	}
+}
+
+func TestCheckKey(t *testing.T) {
+	valid := []string{"a", "a.txt", "2024/01/a.txt", "a..b/c"}
+	invalid := []string{"", "/a", "../a", "a/../../b", "a/./b", "a//b", "a/", ".", "..", `a\..\b`, "a/.."}
+	for _, key := range valid {
+		if err := CheckKey(key); err != nil {
+			t.Errorf("CheckKey(%q) = %v, want nil", key, err)
+		}
+	}
+	for _, key := range invalid {
+		if err := CheckKey(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("CheckKey(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+
+	ctx := context.Background()
+	l := newLocal(t)
+	if err := l.Put(ctx, "../escape.txt", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Put outside the root = %v, want ErrInvalidKey", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(l.root), "escape.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("Put wrote outside the root")
+	}
+	if _, err := l.Open(ctx, "../../etc/passwd"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Open outside the root = %v, want ErrInvalidKey", err)
+	}
+	if err := l.Delete(ctx, "/etc/passwd"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Delete outside the root = %v, want ErrInvalidKey", err)
+	}
+	if _, err := l.PresignGet(ctx, "a/../../b", "", time.Minute); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("PresignGet outside the root = %v, want ErrInvalidKey", err)
+	}
+}
+
+// presign 返回 PresignGet 地址中的 key 与查询参数
+func presign(t *testing.T, l *Local, key, filename string, expire time.Duration) (string, url.Values) {
+	t.Helper()
+	raw, err := l.PresignGet(context.Background(), key, filename, expire)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	path, ok := strings.CutPrefix(u.Path, "/api/v1/files/download/")
+	if !ok {
+		t.Fatalf("url %q does not start with the base URL", raw)
+	}
+	return path, u.Query()
+}
+
+func TestLocalVerify(t *testing.T) {
+	l := newLocal(t)
+
+	key, q := presign(t, l, "2024/01/报告 1.pdf", "报告 1.pdf", time.Minute)
+	if key != "2024/01/报告 1.pdf" {
+		t.Fatalf("key = %q", key)
+	}
+	if err := l.Verify(key, q); err != nil {
+		t.Fatalf("Verify of a valid url = %v", err)
+	}
+
+	tampered := map[string]func(url.Values) (string, url.Values){
+		"key": func(q url.Values) (string, url.Values) { return "2024/01/other.pdf", q },
+		"filename": func(q url.Values) (string, url.Values) {
+			q.Set("filename", "other.pdf")
+			return key, q
+		},
+		"expires": func(q url.Values) (string, url.Values) {
+			q.Set("expires", "99999999999")
+			return key, q
+		},
+		"signature": func(q url.Values) (string, url.Values) {
+			q.Set("signature", strings.Repeat("A", len(q.Get("signature"))))
+			return key, q
+		},
+		"missing signature": func(q url.Values) (string, url.Values) {
+			q.Del("signature")
+			return key, q
+		},
+	}
+	for name, tamper := range tampered {
+		k, tq := tamper(cloneValues(q))
+		if err := l.Verify(k, tq); !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("Verify with tampered %s = %v, want ErrSignatureInvalid", name, err)
+		}
+	}
+
+	other, err := NewLocal(t.TempDir(), WithSecret([]byte("another secret")))
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	if err := other.Verify(key, q); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify with another secret = %v, want ErrSignatureInvalid", err)
+	}
+
+	key, q = presign(t, l, "a.txt", "", -time.Minute)
+	if err := l.Verify(key, q); !errors.Is(err, ErrURLExpired) {
+		t.Fatalf("Verify of an expired url = %v, want ErrURLExpired", err)
+	}
+}
+
+func cloneValues(q url.Values) url.Values {
+	c := make(url.Values, len(q))
+	for k, v := range q {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"{{cookiecutter.project_name}}/configs/conf"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	defaultRegion = "us-east-1"
+	// streamPartSize 大小未知时分片上传的分片大小，即每个上传占用的缓冲区大小
+	streamPartSize = 5 << 20
+)
+
+// S3 兼容 S3 协议的对象存储，如 AWS S3、MinIO、阿里云 OSS、腾讯云 COS
+type S3 struct {
+	client *minio.Client
+	// presign 生成下载地址的客户端，未配置 publicEndpoint 时与 client 相同
+	presign *minio.Client
+	bucket  string
+}
+
+var _ Storage = (*S3)(nil)
+
+// NewS3 创建 S3 存储，返回前确认 bucket 存在
+func NewS3(ctx context.Context, c *conf.File_S3) (*S3, error) {
+	if c.GetEndpoint() == "" || c.GetBucket() == "" {
+		return nil, errors.New("storage: s3 requires endpoint and bucket")
+	}
+	region := c.GetRegion()
+	if region == "" {
+		region = defaultRegion
+	}
+	lookup := minio.BucketLookupAuto
+	if c.GetPathStyle() {
+		lookup = minio.BucketLookupPath
+	}
+	newClient := func(endpoint string, secure bool) (*minio.Client, error) {
+		return minio.New(endpoint, &minio.Options{
+			Creds:        credentials.NewStaticV4(c.GetAccessKey(), c.GetSecretKey(), ""),
+			Secure:       secure,
+			Region:       region,
+			BucketLookup: lookup,
+		})
+	}
+
+	client, err := newClient(c.GetEndpoint(), c.GetUseSsl())
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 client: %w", err)
+	}
+	s := &S3{client: client, presign: client, bucket: c.GetBucket()}
+	if pe := c.GetPublicEndpoint(); pe != "" {
+		u, err := url.Parse(pe)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("storage: invalid s3 publicEndpoint %q", pe)
+		}
+		if s.presign, err = newClient(u.Host, u.Scheme == "https"); err != nil {
+			return nil, fmt.Errorf("storage: s3 client: %w", err)
+		}
+	}
+
+	ok, err := client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 bucket %s: %w", s.bucket, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("storage: s3 bucket %s does not exist", s.bucket)
+	}
+	return s, nil
+}
+
+// Put 实现 Storage，大小未知时使用分片上传，失败时未完成的分片会被清理
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if size < 0 {
+		// 不超过一个分片的对象直接上传，避免分片上传的三次请求
+		head, err := io.ReadAll(io.LimitReader(r, streamPartSize))
+		if err != nil {
+			return err
+		}
+		if len(head) < streamPartSize {
+			r, size = bytes.NewReader(head), int64(len(head))
+		} else {
+			r = io.MultiReader(bytes.NewReader(head), r)
+			opts.PartSize = streamPartSize
+		}
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, opts)
+	return err
+}
+
+// Open 实现 Storage
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.error(err)
+	}
+	// GetObject 不发送请求，通过 Stat 确认对象存在
+	if _, err := obj.Stat(); err != nil {
+		_ = obj.Close()
+		return nil, s.error(err)
+	}
+	return obj, nil
+}
+
+// Delete 实现 Storage
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		if err := s.error(err); !errors.Is(err, ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresignGet 实现 Storage，返回 S3 预签名地址，客户端直接从对象存储下载
+func (s *S3) PresignGet(ctx context.Context, key, filename string, expire time.Duration) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	params := url.Values{"response-content-disposition": {ContentDisposition(filename)}}
+	u, err := s.presign.PresignedGetObject(ctx, s.bucket, key, expire, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *S3) error(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotExist
+	}
+	return err
+}
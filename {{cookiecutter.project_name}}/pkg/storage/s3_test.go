@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"{{cookiecutter.project_name}}/configs/conf"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+const testBucket = "files"
+
+// newS3 返回连接到进程内 S3 的存储
+func newS3(t *testing.T) (*S3, *httptest.Server) {
+	t.Helper()
+	backend := s3mem.New()
+	if err := backend.CreateBucket(testBucket); err != nil {
+		t.Fatalf("CreateBucket: %v", err)
+	}
+	srv := httptest.NewServer(decodeChunkedParts(gofakes3.New(backend).Server()))
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3(context.Background(), &conf.File_S3{
+		Endpoint:       strings.TrimPrefix(srv.URL, "http://"),
+		Bucket:         testBucket,
+		AccessKey:      "access",
+		SecretKey:      "secret",
+		PathStyle:      true,
+		PublicEndpoint: srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	return s, srv
+}
+
+// decodeChunkedParts 解码分片上传的 aws-chunked 请求体
+// minio-go 在 HTTP 连接上使用带分片签名的流式上传，gofakes3 只在 PutObject 中去除分片签名
+func decodeChunkedParts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Content-Sha256") != "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" || !r.URL.Query().Has("partNumber") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var body bytes.Buffer
+		br := bufio.NewReader(r.Body)
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			hex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+			size, err := strconv.ParseInt(hex, 16, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err := io.CopyN(&body, br, size); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if _, err := br.Discard(2); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		r.Body = io.NopCloser(&body)
+		r.ContentLength = int64(body.Len())
+		r.Header.Set("Content-Length", strconv.Itoa(body.Len()))
+		r.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestNewS3MissingBucket(t *testing.T) {
+	_, srv := newS3(t)
+	_, err := NewS3(context.Background(), &conf.File_S3{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Bucket:    "missing",
+		PathStyle: true,
+	})
+	if err == nil {
+		t.Fatal("NewS3 with a missing bucket should fail")
+	}
+}
+
+func TestS3PutUnknownSize(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newS3(t)
+
+	small := "hello"
+	// 超过一个分片，走分片上传
+	large := bytes.Repeat([]byte("0123456789abcdef"), streamPartSize/16+1024)
+	cases := map[string][]byte{"small.txt": []byte(small), "large.bin": large}
+	for key, content := range cases {
+		// 包装为 io.Reader，隐藏 bytes.Reader 的长度
+		r := io.MultiReader(bytes.NewReader(content))
+		if err := s.Put(ctx, key, r, -1, "application/octet-stream"); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+		if got := readAll(t, s, key); got != string(content) {
+			t.Fatalf("content of %q has %d bytes, want %d", key, len(got), len(content))
+		}
+	}
+
+	if err := s.Put(ctx, "known.txt", strings.NewReader(small), int64(len(small)), "text/plain"); err != nil {
+		t.Fatalf("Put with known size: %v", err)
+	}
+	if got := readAll(t, s, "known.txt"); got != small {
+		t.Fatalf("content = %q, want %q", got, small)
+	}
+}
+
+func TestS3OpenDelete(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newS3(t)
+
+	if _, err := s.Open(ctx, "missing.txt"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("Open of a missing key = %v, want ErrNotExist", err)
+	}
+	if err := s.Put(ctx, "a.txt", strings.NewReader("a"), 1, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Delete(ctx, "a.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Open(ctx, "a.txt"); !errors.Is(err, ErrNotExist) {
+		t.Fatalf("Open after Delete = %v, want ErrNotExist", err)
+	}
+	if err := s.Delete(ctx, "a.txt"); err != nil {
+		t.Fatalf("Delete of a missing key = %v, want nil", err)
+	}
+	if _, err := s.Open(ctx, "../a.txt"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Open with an invalid key = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestS3PresignGet(t *testing.T) {
+	ctx := context.Background()
+	s, srv := newS3(t)
+
+	if err := s.Put(ctx, "2024/report.pdf", strings.NewReader("pdf"), 3, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	raw, err := s.PresignGet(ctx, "2024/report.pdf", "报告.pdf", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	if got := u.Scheme + "://" + u.Host; got != srv.URL {
+		t.Fatalf("presigned host = %q, want the public endpoint %q", got, srv.URL)
+	}
+	if u.Path != "/"+testBucket+"/2024/report.pdf" {
+		t.Fatalf("presigned path = %q", u.Path)
+	}
+	q := u.Query()
+	if q.Get("X-Amz-Signature") == "" || q.Get("X-Amz-Expires") != "60" {
+		t.Fatalf("presigned query %v lacks the signature or expiry", q)
+	}
+	if got, want := q.Get("response-content-disposition"), ContentDisposition("报告.pdf"); got != want {
+		t.Fatalf("response-content-disposition = %q, want %q", got, want)
+	}
+
+	resp, err := http.Get(raw)
+	if err != nil {
+		t.Fatalf("GET presigned url: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "pdf" {
+		t.Fatalf("GET presigned url = %d %q, want 200 \"pdf\"", resp.StatusCode, body)
+	}
+}
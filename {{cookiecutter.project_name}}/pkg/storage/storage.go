@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotExist 对象不存在
+	ErrNotExist = errors.New("storage: object does not exist")
+	// ErrInvalidKey 对象 key 为空、为绝对路径或包含 ".."
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+// Storage 对象存储
+type Storage interface {
+	// Put 写入对象，size 未知时为 -1；r 返回错误时不保留已写入的部分
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open 读取对象，不存在时返回 ErrNotExist
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// PresignGet 返回 expire 内有效的下载地址，下载时以 filename 作为附件名
+	PresignGet(ctx context.Context, key, filename string, expire time.Duration) (string, error)
+}
+
+// CheckKey 校验对象 key，key 使用 / 分隔且不能逃逸出存储根目录
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." || part == "." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// ContentDisposition 返回以 filename 作为附件名的 Content-Disposition
+func ContentDisposition(filename string) string {
+	if filename == "" {
+		return "attachment"
+	}
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
@@ -0,0 +1,108 @@
+package tlsconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"{{cookiecutter.project_name}}/configs/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// checkInterval 两次检查证书文件修改时间的最小间隔
+const checkInterval = 5 * time.Second
+
+// files 证书、私钥与 CA 文件，握手时按 checkInterval 检查修改时间，变更后重新加载
+// 重新加载失败时继续使用原证书
+type files struct {
+	certFile, keyFile, caFile string
+
+	mu      sync.Mutex
+	checked time.Time
+	modTime [3]time.Time
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	log     *log.Helper
+}
+
+func load(c *conf.TLS, logger log.Logger) (*files, error) {
+	f := &files{
+		certFile: c.GetCertFile(),
+		keyFile:  c.GetKeyFile(),
+		caFile:   c.GetCaFile(),
+		log:      log.NewHelper(log.With(logger, "module", "tlsconf")),
+	}
+	modTime, err := f.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.reload(modTime); err != nil {
+		return nil, err
+	}
+	f.checked = time.Now()
+	return f, nil
+}
+
+// get 返回当前的证书与 CA，未配置时为 nil
+func (f *files) get() (*tls.Certificate, *x509.CertPool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if now := time.Now(); now.Sub(f.checked) >= checkInterval {
+		f.checked = now
+		modTime, err := f.stat()
+		if err != nil {
+			f.log.Warnf("failed to stat tls files, keep the loaded certificates: %v", err)
+		} else if modTime != f.modTime {
+			if err := f.reload(modTime); err != nil {
+				f.log.Warnf("failed to reload tls files, keep the loaded certificates: %v", err)
+			} else {
+				f.log.Infof("tls files reloaded: %s %s %s", f.certFile, f.keyFile, f.caFile)
+			}
+		}
+	}
+	return f.cert, f.pool
+}
+
+// stat 返回各文件的修改时间，os.Stat 跟随符号链接，可感知 Kubernetes Secret 的更新
+func (f *files) stat() ([3]time.Time, error) {
+	var modTime [3]time.Time
+	for i, name := range []string{f.certFile, f.keyFile, f.caFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return modTime, fmt.Errorf("tls: %w", err)
+		}
+		modTime[i] = info.ModTime()
+	}
+	return modTime, nil
+}
+
+func (f *files) reload(modTime [3]time.Time) error {
+	var cert *tls.Certificate
+	if f.certFile != "" {
+		c, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+		if err != nil {
+			return fmt.Errorf("tls: load key pair: %w", err)
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if f.caFile != "" {
+		pem, err := os.ReadFile(f.caFile)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tls: no certificate found in %s", f.caFile)
+		}
+	}
+	f.cert, f.pool, f.modTime = cert, pool, modTime
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package tlsconf 根据 conf.TLS 创建服务端与客户端的 tls.Config，证书文件变更后自动重新加载
+package tlsconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"{{cookiecutter.project_name}}/configs/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// clientAuth 客户端认证方式，verify 表示需要用 caFile 校验客户端证书
+type clientAuth struct {
+	typ    tls.ClientAuthType
+	verify bool
+}
+
+var clientAuths = map[string]clientAuth{
+	"none":               {typ: tls.NoClientCert},
+	"request":            {typ: tls.RequestClientCert},
+	"require":            {typ: tls.RequireAnyClientCert},
+	"verify-if-given":    {typ: tls.RequestClientCert, verify: true},
+	"require-and-verify": {typ: tls.RequireAnyClientCert, verify: true},
+}
+
+// Server 创建服务端 tls.Config，未启用时返回 nil
+func Server(c *conf.TLS, logger log.Logger) (*tls.Config, error) {
+	if !c.GetEnable() {
+		return nil, nil
+	}
+	if c.GetCertFile() == "" || c.GetKeyFile() == "" {
+		return nil, errors.New("tls: certFile and keyFile are required by a server")
+	}
+	version, err := parseVersion(c.GetMinVersion())
+	if err != nil {
+		return nil, err
+	}
+	mode := c.GetClientAuth()
+	if mode == "" {
+		mode = "none"
+		if c.GetCaFile() != "" {
+			mode = "require-and-verify"
+		}
+	}
+	ca, ok := clientAuths[mode]
+	if !ok {
+		return nil, fmt.Errorf("tls: unknown clientAuth %q", mode)
+	}
+	if ca.verify && c.GetCaFile() == "" {
+		return nil, fmt.Errorf("tls: clientAuth %q requires caFile", mode)
+	}
+	f, err := load(c, logger)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion: version,
+		ClientAuth: ca.typ,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := f.get()
+			return cert, nil
+		},
+	}
+	if ca.verify {
+		// 由 ClientAuth 要求客户端出示证书，再用最新加载的 CA 校验，CA 文件变更时无需重建配置
+		cfg.VerifyPeerCertificate = func(raw [][]byte, _ [][]*x509.Certificate) error {
+			if len(raw) == 0 {
+				return nil
+			}
+			_, pool := f.get()
+			return verify(raw, pool, x509.VerifyOptions{KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		}
+	}
+	return cfg, nil
+}
+
+// Client 创建客户端 tls.Config，未启用时返回 nil
+func Client(c *conf.TLS, logger log.Logger) (*tls.Config, error) {
+	if !c.GetEnable() {
+		return nil, nil
+	}
+	if (c.GetCertFile() == "") != (c.GetKeyFile() == "") {
+		return nil, errors.New("tls: certFile and keyFile must be set together")
+	}
+	version, err := parseVersion(c.GetMinVersion())
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:         version,
+		ServerName:         c.GetServerName(),
+		InsecureSkipVerify: c.GetInsecureSkipVerify(),
+	}
+	if c.GetCertFile() == "" && c.GetCaFile() == "" {
+		return cfg, nil
+	}
+	f, err := load(c, logger)
+	if err != nil {
+		return nil, err
+	}
+	if c.GetCertFile() != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := f.get()
+			return cert, nil
+		}
+	}
+	if c.GetCaFile() != "" && !c.GetInsecureSkipVerify() {
+		// 跳过内置校验，改用最新加载的 CA 校验服务端证书
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			raw := make([][]byte, 0, len(cs.PeerCertificates))
+			for _, cert := range cs.PeerCertificates {
+				raw = append(raw, cert.Raw)
+			}
+			_, pool := f.get()
+			return verify(raw, pool, x509.VerifyOptions{DNSName: cs.ServerName})
+		}
+	}
+	return cfg, nil
+}
+
+// verify 用 roots 校验证书链，raw[0] 为对端证书
+func verify(raw [][]byte, roots *x509.CertPool, opts x509.VerifyOptions) error {
+	if len(raw) == 0 {
+		return errors.New("tls: no peer certificate")
+	}
+	certs := make([]*x509.Certificate, 0, len(raw))
+	for _, b := range raw {
+		cert, err := x509.ParseCertificate(b)
+		if err != nil {
+			return fmt.Errorf("tls: parse peer certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	opts.Roots = roots
+	opts.Intermediates = x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
+}
+
+func parseVersion(s string) (uint16, error) {
+	if s == "" {
+		return tls.VersionTLS12, nil
+	}
+	v, ok := versions[strings.TrimPrefix(strings.ToLower(s), "tls")]
+	if !ok {
+		return 0, fmt.Errorf("tls: unknown minVersion %q", s)
+	}
+	return v, nil
+}